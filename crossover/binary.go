@@ -5,12 +5,14 @@ import (
 	"math/rand/v2"
 	"reflect"
 	"slices"
+
+	"github.com/mbolis/genetta/layout"
 )
 
 type binary struct{}
 
 func (binary) IsCompatible(chromosomeType reflect.Kind, flags uint) bool {
-	return chromosomeType == reflect.Int // TODO no permutation should be allowed
+	return chromosomeType == reflect.Int && flags&layout.FlagPermutation == 0
 }
 
 type kPoints struct {
@@ -19,6 +21,11 @@ type kPoints struct {
 	k      int
 	xps    []int
 	buffer []int
+
+	// genes restricts the crossover points to the boundaries between genes,
+	// which are only known once bound to a chromosome
+	genes      bool
+	boundaries []int
 }
 
 func KPoints(k int) Operator {
@@ -36,9 +43,36 @@ func TwoPoints() Operator {
 	return KPoints(2)
 }
 
+// GeneKPoints works like KPoints, but only cuts the chromosomes between
+// genes, so that each gene of the children comes whole from either parent.
+func GeneKPoints(k int) Operator {
+	if k <= 0 {
+		panic(fmt.Sprintf("invalid k-point crossover: k= %d", k)) // TODO
+	}
+	return &kPoints{k: k, xps: make([]int, k), genes: true}
+}
+
+func (s *kPoints) Clone() Operator {
+	return &kPoints{k: s.k, xps: make([]int, s.k), genes: s.genes, boundaries: s.boundaries}
+}
+
+func (s *kPoints) Bind(l layout.Chromosome) Operator {
+	if !s.genes {
+		return s
+	}
+	return &kPoints{k: s.k, xps: make([]int, s.k), genes: true, boundaries: l.Boundaries()}
+}
+
 func (s *kPoints) Crossover(mom, dad, child1, child2 []byte) error {
 	totBits := len(mom) * 8
-	if s.k >= totBits {
+	if s.genes {
+		if s.boundaries == nil {
+			return fmt.Errorf("gene-aware crossover is not bound to any gene layout")
+		}
+		if s.k > len(s.boundaries) {
+			return fmt.Errorf("cannot apply %d-point crossover to chromosomes of %d genes", s.k, len(s.boundaries)+1)
+		}
+	} else if s.k >= totBits {
 		return fmt.Errorf("cannot apply %d-point crossover to chromosomes %d bits long", s.k, totBits)
 	}
 
@@ -85,6 +119,17 @@ func (s *kPoints) Crossover(mom, dad, child1, child2 []byte) error {
 }
 
 func (s *kPoints) randomXPoints(totBits int) []int {
+	if s.genes {
+		if len(s.buffer) != len(s.boundaries) {
+			s.buffer = slices.Clone(s.boundaries)
+		}
+		rand.Shuffle(len(s.buffer), s.swapBuffer)
+
+		copy(s.xps, s.buffer)
+		slices.Sort(s.xps)
+		return s.xps
+	}
+
 	xpRange := totBits - 2
 	if len(s.buffer) == xpRange {
 		// no need to change anything
@@ -113,9 +158,8 @@ type uniform struct {
 	rate float64
 }
 
-// TODO uniform evolving children separately
-
-// TODO coarser grained option? k-size blocks
+// ParametricHalfUniform swaps each bit where the parents differ with
+// probability rate, so that the children are always complementary.
 func ParametricHalfUniform(rate float64) Operator {
 	return uniform{rate: rate}
 }
@@ -138,6 +182,128 @@ func (u uniform) Crossover(mom, dad, child1, child2 []byte) error {
 	return nil
 }
 
+type independentUniform struct {
+	binary
+
+	p float64
+}
+
+// Uniform builds each child from its own random mask: each bit of child1 comes
+// from dad with probability p, and from mom otherwise, and the other way round
+// for child2. Unlike ParametricHalfUniform, children are not complementary.
+func Uniform(p float64) Operator {
+	if p < 0 || p > 1 {
+		panic(fmt.Sprintf("invalid uniform crossover probability: %f", p)) // TODO
+	}
+	return independentUniform{p: p}
+}
+
+func (u independentUniform) Crossover(mom, dad, child1, child2 []byte) error {
+	for i := range len(mom) {
+		m1 := randomMask(u.p)
+		m2 := randomMask(u.p)
+
+		m, d := mom[i], dad[i]
+		child1[i] = m&^m1 | d&m1
+		child2[i] = d&^m2 | m&m2
+	}
+	return nil
+}
+
+// randomMask returns a byte whose bits are set each with probability p.
+func randomMask(p float64) (mask byte) {
+	if p == 0.5 {
+		return byte(rand.Uint32())
+	}
+	for j := range 8 {
+		if rand.Float64() < p {
+			mask |= byte(1) << j
+		}
+	}
+	return
+}
+
+type blockUniform struct {
+	binary
+
+	k int
+	p float64
+}
+
+// BlockUniform splits the chromosomes into aligned blocks of k bits, the last
+// one being possibly shorter, and swaps each block between the children with
+// probability p. With k matching the size of the genes, genes are exchanged
+// whole.
+func BlockUniform(k int, p float64) Operator {
+	if k <= 0 {
+		panic(fmt.Sprintf("invalid block size: %d", k)) // TODO
+	}
+	if p < 0 || p > 1 {
+		panic(fmt.Sprintf("invalid uniform crossover probability: %f", p)) // TODO
+	}
+	return blockUniform{k: k, p: p}
+}
+
+func (b blockUniform) Crossover(mom, dad, child1, child2 []byte) error {
+	copy(child1, mom)
+	copy(child2, dad)
+
+	totBits := len(mom) * 8
+	for lo := 0; lo < totBits; lo += b.k {
+		if rand.Float64() >= b.p {
+			continue
+		}
+
+		flipBits(child1, child2, lo, min(lo+b.k, totBits))
+	}
+	return nil
+}
+
+type geneUniform struct {
+	binary
+
+	p     float64
+	genes []layout.Gene
+}
+
+// GeneUniform swaps each gene whole between the children with probability p.
+func GeneUniform(p float64) Operator {
+	if p < 0 || p > 1 {
+		panic(fmt.Sprintf("invalid uniform crossover probability: %f", p)) // TODO
+	}
+	return geneUniform{p: p}
+}
+
+func (g geneUniform) Bind(l layout.Chromosome) Operator {
+	return geneUniform{p: g.p, genes: l.Genes}
+}
+
+func (g geneUniform) Crossover(mom, dad, child1, child2 []byte) error {
+	if g.genes == nil {
+		return fmt.Errorf("gene-aware crossover is not bound to any gene layout")
+	}
+
+	copy(child1, mom)
+	copy(child2, dad)
+
+	for _, gene := range g.genes {
+		if rand.Float64() < g.p {
+			flipBits(child1, child2, gene.Offset, gene.Offset+gene.Width)
+		}
+	}
+	return nil
+}
+
+// flipBits swaps bits lo to hi, excluded, between a and b.
+func flipBits(a, b []byte, lo, hi int) {
+	for i := lo / 8; i <= (hi-1)/8; i++ {
+		from := max(lo-i*8, 0)
+		to := min(hi-i*8, 8)
+		mask := byte(0xff) >> (8 - to + from) << from
+		flip(a, b, i, mask)
+	}
+}
+
 func flip(a, b []byte, i int, mask byte) {
 	av := a[i]
 	bv := b[i]
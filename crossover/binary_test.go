@@ -4,11 +4,13 @@ import (
 	"fmt"
 	"math/bits"
 	"math/rand/v2"
+	"reflect"
 	"testing"
 	"unsafe"
 
 	"github.com/mbolis/genetta/crossover"
 	"github.com/mbolis/genetta/internal/testutil/distcheck"
+	"github.com/mbolis/genetta/layout"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -203,3 +205,186 @@ func TestParametricHalfUniform(t *testing.T) {
 		})
 	}
 }
+
+func TestUniform(t *testing.T) {
+	for _, p := range []float64{0.25, 0.5} {
+		t.Run(fmt.Sprintf("should take %d%% of the bits from the other parent", int(p*100)), func(t *testing.T) {
+			u := crossover.Uniform(p)
+			mom := []byte{0xaa, 0xaa, 0xaa, 0xaa}
+			dad := []byte{0x55, 0x55, 0x55, 0x55}
+
+			var flipped1, flipped2, equal int
+			for range repeats {
+				var child1, child2 [4]byte
+
+				err := u.Crossover(mom, dad, child1[:], child2[:])
+				assert.NoError(t, err)
+
+				for i := range child1 {
+					flipped1 += bits.OnesCount8(child1[i] ^ mom[i])
+					flipped2 += bits.OnesCount8(child2[i] ^ dad[i])
+					equal += 8 - bits.OnesCount8(child1[i]^child2[i])
+				}
+			}
+
+			totBits := float64(repeats * len(mom) * 8)
+			epsilon := 0.01
+			assert.InEpsilon(t, p, float64(flipped1)/totBits, epsilon)
+			assert.InEpsilon(t, p, float64(flipped2)/totBits, epsilon)
+			// children are the same where exactly one of them took the bit
+			// from the other parent
+			assert.InEpsilon(t, 2*p*(1-p), float64(equal)/totBits, epsilon)
+		})
+	}
+}
+
+func TestBlockUniform(t *testing.T) {
+	for _, k := range []int{4, 8, 12} {
+		t.Run(fmt.Sprintf("should swap whole %d-bit blocks", k), func(t *testing.T) {
+			bu := crossover.BlockUniform(k, 0.5)
+			mom := []byte{0x00, 0x00, 0x00, 0x00}
+			dad := []byte{0xff, 0xff, 0xff, 0xff}
+
+			nBlocks := (32 + k - 1) / k
+			var swapped int
+			for range repeats {
+				var child1, child2 [4]byte
+
+				err := bu.Crossover(mom, dad, child1[:], child2[:])
+				assert.NoError(t, err)
+
+				c1 := uint32(child1[0]) | uint32(child1[1])<<8 | uint32(child1[2])<<16 | uint32(child1[3])<<24
+				c2 := uint32(child2[0]) | uint32(child2[1])<<8 | uint32(child2[2])<<16 | uint32(child2[3])<<24
+				require.Equal(t, ^c1, c2)
+
+				for b := range nBlocks {
+					width := min(k, 32-b*k)
+					block := c1 >> (b * k) & (1<<width - 1)
+					if block != 0 {
+						require.Equal(t, uint32(1<<width-1), block, "blocks should not be split")
+						swapped++
+					}
+				}
+			}
+
+			assert.InEpsilon(t, 0.5, float64(swapped)/float64(repeats*nBlocks), 0.02)
+		})
+	}
+}
+
+// geneLayout describes three genes of 12, 12 and 6 bits, followed by 2 bits of
+// padding
+var geneLayout = layout.Chromosome{
+	Genes: []layout.Gene{
+		{Offset: 0, Width: 12, Kind: reflect.Uint16},
+		{Offset: 12, Width: 12, Kind: reflect.Uint16},
+		{Offset: 24, Width: 6, Kind: reflect.Uint8},
+	},
+	Bytes: 4,
+}
+
+// geneSources tells, for each gene of geneLayout, whether child took it from
+// a parent made of all 1s, failing if a gene mixes bits of both parents
+func geneSources(t *testing.T, child []byte) (fromOnes []bool) {
+	t.Helper()
+
+	c := uint32(child[0]) | uint32(child[1])<<8 | uint32(child[2])<<16 | uint32(child[3])<<24
+	for _, g := range geneLayout.Genes {
+		mask := uint32(1)<<g.Width - 1
+		value := c >> g.Offset & mask
+		require.True(t, value == 0 || value == mask, "gene at %d was split", g.Offset)
+		fromOnes = append(fromOnes, value == mask)
+	}
+	return
+}
+
+func TestGeneKPoints(t *testing.T) {
+	mom := []byte{0x00, 0x00, 0x00, 0x00}
+	dad := []byte{0xff, 0xff, 0xff, 0xff}
+
+	t.Run("should cut between genes only", func(t *testing.T) {
+		kp := crossover.Bind(crossover.GeneKPoints(1), geneLayout)
+
+		counts := map[[3]bool]int{}
+		for range repeats {
+			var child1, child2 [4]byte
+
+			err := kp.Crossover(mom, dad, child1[:], child2[:])
+			require.NoError(t, err)
+
+			sources := geneSources(t, child1[:])
+			assert.False(t, sources[0])
+			for i, s := range geneSources(t, child2[:]) {
+				assert.Equal(t, !sources[i], s)
+			}
+			counts[[3]bool(sources)]++
+		}
+
+		assert.Len(t, counts, 2)
+		assert.InEpsilon(t, repeats/2, counts[[3]bool{false, true, true}], 0.1)
+	})
+	t.Run("should alternate parents at each cut", func(t *testing.T) {
+		kp := crossover.Bind(crossover.GeneKPoints(2), geneLayout)
+
+		var child1, child2 [4]byte
+		require.NoError(t, kp.Crossover(mom, dad, child1[:], child2[:]))
+		assert.Equal(t, []bool{false, true, false}, geneSources(t, child1[:]))
+	})
+	t.Run("should fail when unbound or with too many points", func(t *testing.T) {
+		var child1, child2 [4]byte
+		assert.Error(t, crossover.GeneKPoints(1).Crossover(mom, dad, child1[:], child2[:]))
+
+		kp := crossover.Bind(crossover.GeneKPoints(3), geneLayout)
+		assert.Error(t, kp.Crossover(mom, dad, child1[:], child2[:]))
+	})
+}
+
+func TestGeneUniform(t *testing.T) {
+	gu := crossover.Bind(crossover.Probability(1, crossover.GeneUniform(0.5)), geneLayout)
+	mom := []byte{0x00, 0x00, 0x00, 0x00}
+	dad := []byte{0xff, 0xff, 0xff, 0xff}
+
+	swapped := make([]int, len(geneLayout.Genes))
+	for range repeats {
+		var child1, child2 [4]byte
+
+		err := gu.Crossover(mom, dad, child1[:], child2[:])
+		require.NoError(t, err)
+
+		sources := geneSources(t, child1[:])
+		for i, s := range geneSources(t, child2[:]) {
+			assert.Equal(t, !sources[i], s)
+			if sources[i] {
+				swapped[i]++
+			}
+		}
+	}
+
+	for _, s := range swapped {
+		assert.InEpsilon(t, repeats/2, s, 0.05)
+	}
+}
+
+func TestClone(t *testing.T) {
+	kp := crossover.Probability(1, crossover.TwoPoints())
+	clone := crossover.Clone(kp)
+
+	mom := []byte{0xaa, 0xaa, 0xaa, 0xaa}
+	dad := []byte{0x55, 0x55, 0x55, 0x55}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range repeats {
+			var child1, child2 [4]byte
+			assert.NoError(t, clone.Crossover(mom, dad, child1[:], child2[:]))
+			assert.Len(t, findCrossoverPoints(0xaa, 0x55, child1[:]), 2)
+		}
+	}()
+	for range repeats {
+		var child1, child2 [4]byte
+		assert.NoError(t, kp.Crossover(mom, dad, child1[:], child2[:]))
+		assert.Len(t, findCrossoverPoints(0xaa, 0x55, child1[:]), 2)
+	}
+	<-done
+}
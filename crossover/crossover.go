@@ -3,6 +3,8 @@ package crossover
 import (
 	"math/rand/v2"
 	"reflect"
+
+	"github.com/mbolis/genetta/layout"
 )
 
 type Operator interface {
@@ -10,6 +12,35 @@ type Operator interface {
 	IsCompatible(chromosomeType reflect.Kind, flags uint) bool
 }
 
+// Cloner is implemented by operators holding scratch state, which therefore
+// cannot be shared among goroutines.
+type Cloner interface {
+	Clone() Operator
+}
+
+// Clone returns a copy of op that can be used concurrently with op itself.
+func Clone(op Operator) Operator {
+	if c, ok := op.(Cloner); ok {
+		return c.Clone()
+	}
+	return op
+}
+
+// Binder is implemented by operators that need to know the layout of the
+// genes within the chromosome they are applied to.
+type Binder interface {
+	Bind(layout.Chromosome) Operator
+}
+
+// Bind returns a copy of op aware of the layout of the chromosome, or op
+// itself if it does not care.
+func Bind(op Operator, l layout.Chromosome) Operator {
+	if b, ok := op.(Binder); ok {
+		return b.Bind(l)
+	}
+	return op
+}
+
 type probability struct {
 	probability float64
 	Operator
@@ -28,3 +59,11 @@ func (p probability) Crossover(mom, dad, child1, child2 []byte) error {
 
 	return p.Operator.Crossover(mom, dad, child1, child2)
 }
+
+func (p probability) Clone() Operator {
+	return probability{p.probability, Clone(p.Operator)}
+}
+
+func (p probability) Bind(l layout.Chromosome) Operator {
+	return probability{p.probability, Bind(p.Operator, l)}
+}
@@ -0,0 +1,311 @@
+package crossover
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"reflect"
+	"slices"
+
+	"github.com/mbolis/genetta/layout"
+)
+
+var errPermutationUnbound = errors.New("permutation crossover is not bound to any gene layout")
+
+// permutation operators decode both parents into permutations of 0..n-1, let
+// prepare draw whatever both children share, and let cross build each child
+// out of the parents, the first one being the one the child mostly resembles.
+type permutation struct {
+	prepare func(p *permutation)
+	cross   func(p *permutation, first, second, child []int)
+
+	genes                    []layout.Gene
+	mom, dad, child1, child2 []int
+
+	// scratch state shared by both children of a crossover
+	lo, hi   int
+	selected []bool
+
+	// scratch state of a single child
+	pos   []int
+	used  []bool
+	edges [][4]int
+	nEdge []int
+}
+
+func newPermutation(prepare func(p *permutation), cross func(p *permutation, first, second, child []int)) Operator {
+	return &permutation{prepare: prepare, cross: cross}
+}
+
+func (*permutation) IsCompatible(chromosomeType reflect.Kind, flags uint) bool {
+	return chromosomeType == reflect.Int && flags&layout.FlagPermutation != 0
+}
+
+func (p *permutation) Bind(l layout.Chromosome) Operator {
+	n := len(l.Genes)
+	return &permutation{
+		prepare:  p.prepare,
+		cross:    p.cross,
+		genes:    l.Genes,
+		mom:      make([]int, n),
+		dad:      make([]int, n),
+		child1:   make([]int, n),
+		child2:   make([]int, n),
+		selected: make([]bool, n),
+		pos:      make([]int, n),
+		used:     make([]bool, n),
+	}
+}
+
+func (p *permutation) Clone() Operator {
+	if p.genes == nil {
+		return &permutation{prepare: p.prepare, cross: p.cross}
+	}
+	return p.Bind(layout.Chromosome{Genes: p.genes})
+}
+
+func (p *permutation) Crossover(mom, dad, child1, child2 []byte) error {
+	if p.genes == nil {
+		return errPermutationUnbound
+	}
+	if err := p.decode(mom, p.mom); err != nil {
+		return err
+	}
+	if err := p.decode(dad, p.dad); err != nil {
+		return err
+	}
+
+	copy(child1, mom)
+	copy(child2, dad)
+	if len(p.genes) == 0 {
+		return nil
+	}
+
+	if p.prepare != nil {
+		p.prepare(p)
+	}
+	p.cross(p, p.mom, p.dad, p.child1)
+	p.cross(p, p.dad, p.mom, p.child2)
+
+	for i, g := range p.genes {
+		g.SetUint(child1, uint64(p.child1[i]))
+		g.SetUint(child2, uint64(p.child2[i]))
+	}
+	return nil
+}
+
+// cutSegment picks a random non-empty segment [lo, hi).
+func cutSegment(p *permutation) {
+	n := len(p.genes)
+	p.lo = rand.IntN(n)
+	p.hi = p.lo + 1 + rand.IntN(n-p.lo)
+}
+
+func (p *permutation) decode(data []byte, perm []int) error {
+	clear(p.used)
+	for i, g := range p.genes {
+		v := g.Uint(data)
+		if v >= uint64(len(p.genes)) || p.used[v] {
+			return fmt.Errorf("chromosome is not a permutation: gene %d is %d", i, v)
+		}
+		p.used[v] = true
+		perm[i] = int(v)
+	}
+	return nil
+}
+
+// PMX is the partially mapped crossover: each child takes a random segment
+// from its first parent, and the rest from the other one, following the
+// mapping defined by the segment wherever a value is already taken.
+func PMX() Operator {
+	return newPermutation(cutSegment, pmx)
+}
+
+func pmx(p *permutation, first, second, child []int) {
+	copy(child, second)
+	for i, v := range child {
+		p.pos[v] = i
+	}
+
+	// swapping each value of the segment into place is the same as following
+	// the mapping
+	for i := p.lo; i < p.hi; i++ {
+		v := first[i]
+		j := p.pos[v]
+		child[i], child[j] = child[j], child[i]
+		p.pos[child[i]], p.pos[child[j]] = i, j
+	}
+}
+
+// OX1 is the order crossover: each child takes a random segment from its first
+// parent, and the remaining values in the order they appear in the other one,
+// starting right after the segment.
+func OX1() Operator {
+	return newPermutation(cutSegment, ox1)
+}
+
+func ox1(p *permutation, first, second, child []int) {
+	n := len(child)
+	clear(p.used)
+	for i := p.lo; i < p.hi; i++ {
+		child[i] = first[i]
+		p.used[first[i]] = true
+	}
+
+	k := p.hi % n
+	for i := range n {
+		v := second[(p.hi+i)%n]
+		if !p.used[v] {
+			child[k] = v
+			k = (k + 1) % n
+		}
+	}
+}
+
+// CycleCrossover splits the positions into the cycles defined by the parents,
+// and has each child take the values of alternate cycles from either parent,
+// so that each value keeps the position it has in one of the parents.
+func CycleCrossover() Operator {
+	return newPermutation(nil, cycle)
+}
+
+func cycle(p *permutation, first, second, child []int) {
+	for i, v := range first {
+		p.pos[v] = i
+	}
+	clear(p.used)
+
+	odd := false
+	for start := range child {
+		if p.used[start] {
+			continue
+		}
+
+		for i := start; !p.used[i]; i = p.pos[second[i]] {
+			p.used[i] = true
+			if odd {
+				child[i] = second[i]
+			} else {
+				child[i] = first[i]
+			}
+		}
+		odd = !odd
+	}
+}
+
+// PositionBased has each child take the values of its first parent at random
+// positions, picked with probability 1/2 and the same for both children, and
+// the remaining values in the order they appear in the other parent.
+func PositionBased() Operator {
+	return newPermutation(selectPositions, positionBased)
+}
+
+func selectPositions(p *permutation) {
+	for i := range p.selected {
+		p.selected[i] = rand.IntN(2) == 0
+	}
+}
+
+func positionBased(p *permutation, first, second, child []int) {
+	clear(p.used)
+	for i, v := range first {
+		if p.selected[i] {
+			child[i] = v
+			p.used[v] = true
+		}
+	}
+
+	k := 0
+	for _, v := range second {
+		if p.used[v] {
+			continue
+		}
+		for p.selected[k] {
+			k++
+		}
+		child[k] = v
+		k++
+	}
+}
+
+// EdgeRecombination builds each child from the edges of both parents, taken as
+// cycles: starting from the first value of its first parent, it moves each
+// time to the neighbor with the fewest neighbors left, and to a random value
+// when none is left.
+func EdgeRecombination() Operator {
+	return newPermutation(nil, edgeRecombination)
+}
+
+func edgeRecombination(p *permutation, first, second, child []int) {
+	n := len(child)
+	if n == 0 {
+		return
+	}
+	if p.edges == nil {
+		p.edges = make([][4]int, n)
+		p.nEdge = make([]int, n)
+	}
+
+	clear(p.nEdge)
+	for _, parent := range [][]int{first, second} {
+		for i, v := range parent {
+			p.addEdge(v, parent[(i+n-1)%n])
+			p.addEdge(v, parent[(i+1)%n])
+		}
+	}
+
+	clear(p.used)
+	current := first[0]
+	for k := range n {
+		child[k] = current
+		p.used[current] = true
+
+		for _, nb := range p.edges[current][:p.nEdge[current]] {
+			p.removeEdge(nb, current)
+		}
+		if k == n-1 {
+			break
+		}
+
+		next, fewest, ties := -1, 5, 0
+		for _, nb := range p.edges[current][:p.nEdge[current]] {
+			switch {
+			case p.nEdge[nb] < fewest:
+				next, fewest, ties = nb, p.nEdge[nb], 1
+			case p.nEdge[nb] == fewest:
+				ties++
+				if rand.IntN(ties) == 0 {
+					next = nb
+				}
+			}
+		}
+		if next < 0 {
+			next = rand.IntN(n)
+			for p.used[next] {
+				next = (next + 1) % n
+			}
+		}
+		current = next
+	}
+}
+
+func (p *permutation) addEdge(from, to int) {
+	edges := p.edges[from][:p.nEdge[from]]
+	if from == to || slices.Contains(edges, to) {
+		return
+	}
+	p.edges[from][p.nEdge[from]] = to
+	p.nEdge[from]++
+}
+
+func (p *permutation) removeEdge(from, to int) {
+	edges := p.edges[from][:p.nEdge[from]]
+	for i, e := range edges {
+		if e == to {
+			last := len(edges) - 1
+			edges[i] = edges[last]
+			p.nEdge[from] = last
+			return
+		}
+	}
+}
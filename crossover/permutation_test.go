@@ -0,0 +1,230 @@
+package crossover_test
+
+import (
+	"math/rand/v2"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/mbolis/genetta/crossover"
+	"github.com/mbolis/genetta/layout"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// permutationLayout describes 10 genes of 4 bits
+var permutationLayout = func() layout.Chromosome {
+	c := layout.Chromosome{Bytes: 5, Flags: layout.FlagPermutation}
+	for i := range 10 {
+		c.Genes = append(c.Genes, layout.Gene{Offset: i * 4, Width: 4, Kind: reflect.Int})
+	}
+	return c
+}()
+
+func encodePermutation(perm []int) []byte {
+	data := make([]byte, permutationLayout.Bytes)
+	for i, g := range permutationLayout.Genes {
+		g.SetUint(data, uint64(perm[i]))
+	}
+	return data
+}
+
+func decodePermutation(t *testing.T, data []byte) []int {
+	t.Helper()
+
+	perm := make([]int, len(permutationLayout.Genes))
+	for i, g := range permutationLayout.Genes {
+		perm[i] = int(g.Uint(data))
+	}
+	sorted := slices.Sorted(slices.Values(perm))
+	require.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, sorted, "should be a permutation: %v", perm)
+	return perm
+}
+
+func crossPermutations(t *testing.T, op crossover.Operator, mom, dad []int) (child1, child2 []int) {
+	t.Helper()
+
+	c1 := make([]byte, permutationLayout.Bytes)
+	c2 := make([]byte, permutationLayout.Bytes)
+	require.NoError(t, op.Crossover(encodePermutation(mom), encodePermutation(dad), c1, c2))
+	return decodePermutation(t, c1), decodePermutation(t, c2)
+}
+
+var permutationOperators = map[string]func() crossover.Operator{
+	"PMX":                crossover.PMX,
+	"OX1":                crossover.OX1,
+	"cycle":              crossover.CycleCrossover,
+	"position-based":     crossover.PositionBased,
+	"edge recombination": crossover.EdgeRecombination,
+}
+
+func TestPermutationCompatibility(t *testing.T) {
+	for name, newOp := range permutationOperators {
+		op := newOp()
+		assert.True(t, op.IsCompatible(reflect.Int, layout.FlagPermutation), name)
+		assert.False(t, op.IsCompatible(reflect.Int, 0), name)
+
+		var child1, child2 [5]byte
+		mom := encodePermutation(rand.Perm(10))
+		assert.Error(t, op.Crossover(mom, mom, child1[:], child2[:]), "%s should fail when unbound", name)
+
+		op = crossover.Bind(op, permutationLayout)
+		assert.Error(t, op.Crossover(mom, []byte{0xff, 0, 0, 0, 0}, child1[:], child2[:]), "%s should reject non permutations", name)
+	}
+
+	for _, op := range []crossover.Operator{crossover.TwoPoints(), crossover.Uniform(0.5), crossover.GeneKPoints(1)} {
+		assert.False(t, op.IsCompatible(reflect.Int, layout.FlagPermutation))
+	}
+}
+
+func TestPermutationOperators(t *testing.T) {
+	for name, newOp := range permutationOperators {
+		op := crossover.Bind(newOp(), permutationLayout)
+
+		t.Run(name+" should produce permutations", func(t *testing.T) {
+			for range repeats {
+				crossPermutations(t, op, rand.Perm(10), rand.Perm(10))
+			}
+		})
+		t.Run(name+" should reproduce identical parents", func(t *testing.T) {
+			parent := rand.Perm(10)
+			child1, child2 := crossPermutations(t, op, parent, parent)
+			if name == "edge recombination" {
+				// the cycle can be walked either way
+				reversed := append(parent[:1:1], parent[1:]...)
+				slices.Reverse(reversed[1:])
+				assert.Contains(t, [][]int{parent, reversed}, child1)
+				assert.Contains(t, [][]int{parent, reversed}, child2)
+				return
+			}
+			assert.Equal(t, parent, child1)
+			assert.Equal(t, parent, child2)
+		})
+	}
+}
+
+// anySegment reports whether child may have been built by taking segment
+// [lo, hi) of first, and the rest according to rest
+func anySegment(child, first []int, rest func(lo, hi int) []int) bool {
+	n := len(child)
+	for lo := range n {
+		for hi := lo + 1; hi <= n; hi++ {
+			if slices.Equal(child[lo:hi], first[lo:hi]) && slices.Equal(child, rest(lo, hi)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestPMX(t *testing.T) {
+	op := crossover.Bind(crossover.PMX(), permutationLayout)
+
+	for range repeats {
+		mom, dad := rand.Perm(10), rand.Perm(10)
+		child1, child2 := crossPermutations(t, op, mom, dad)
+
+		for _, tc := range [][3][]int{{child1, mom, dad}, {child2, dad, mom}} {
+			child, first, second := tc[0], tc[1], tc[2]
+			assert.True(t, anySegment(child, first, func(lo, hi int) []int {
+				// values of second clashing with the segment are replaced
+				// following the mapping first[i] -> second[i]
+				expected := slices.Clone(second)
+				copy(expected[lo:hi], first[lo:hi])
+				for i := range expected {
+					if i >= lo && i < hi {
+						continue
+					}
+					for v := expected[i]; ; {
+						j := slices.Index(first[lo:hi], v)
+						if j < 0 {
+							expected[i] = v
+							break
+						}
+						v = second[lo+j]
+					}
+				}
+				return expected
+			}), "%v x %v -> %v", first, second, child)
+		}
+	}
+}
+
+func TestCycleCrossover(t *testing.T) {
+	op := crossover.Bind(crossover.CycleCrossover(), permutationLayout)
+
+	mom := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	dad := []int{1, 0, 3, 4, 2, 5, 7, 8, 9, 6}
+
+	child1, child2 := crossPermutations(t, op, mom, dad)
+	assert.Equal(t, []int{0, 1, 3, 4, 2, 5, 7, 8, 9, 6}, child1)
+	assert.Equal(t, []int{1, 0, 2, 3, 4, 5, 6, 7, 8, 9}, child2)
+
+	for range repeats {
+		mom, dad := rand.Perm(10), rand.Perm(10)
+		child1, child2 := crossPermutations(t, op, mom, dad)
+		for i := range child1 {
+			require.True(t, child1[i] == mom[i] || child1[i] == dad[i], "values should keep their positions")
+			require.Equal(t, child1[i] == mom[i], child2[i] == dad[i], "children should be complementary")
+		}
+	}
+}
+
+func TestOX1(t *testing.T) {
+	op := crossover.Bind(crossover.OX1(), permutationLayout)
+
+	for range repeats {
+		mom, dad := rand.Perm(10), rand.Perm(10)
+		child1, child2 := crossPermutations(t, op, mom, dad)
+
+		for _, tc := range [][3][]int{{child1, mom, dad}, {child2, dad, mom}} {
+			child, first, second := tc[0], tc[1], tc[2]
+			assert.True(t, anySegment(child, first, func(lo, hi int) []int {
+				// the other values follow the segment in the order of second,
+				// both starting right after the segment
+				expected := slices.Clone(first)
+				k := hi % 10
+				for i := range 10 {
+					v := second[(hi+i)%10]
+					if !slices.Contains(first[lo:hi], v) {
+						expected[k] = v
+						k = (k + 1) % 10
+					}
+				}
+				return expected
+			}), "%v x %v -> %v", first, second, child)
+		}
+	}
+}
+
+func TestEdgeRecombination(t *testing.T) {
+	op := crossover.Bind(crossover.EdgeRecombination(), permutationLayout)
+
+	var inherited, total int
+	for range repeats {
+		mom, dad := rand.Perm(10), rand.Perm(10)
+		edges := map[[2]int]bool{}
+		for _, p := range [][]int{mom, dad} {
+			for i := range p {
+				a, b := p[i], p[(i+1)%10]
+				edges[[2]int{a, b}] = true
+				edges[[2]int{b, a}] = true
+			}
+		}
+
+		child1, child2 := crossPermutations(t, op, mom, dad)
+		assert.Equal(t, mom[0], child1[0])
+		assert.Equal(t, dad[0], child2[0])
+		for _, c := range [][]int{child1, child2} {
+			for i := range c[:9] {
+				if edges[[2]int{c[i], c[i+1]}] {
+					inherited++
+				}
+				total++
+			}
+		}
+	}
+
+	// random jumps are only needed when stuck, which is rare
+	assert.Greater(t, float64(inherited)/float64(total), 0.9)
+}
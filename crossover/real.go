@@ -0,0 +1,238 @@
+package crossover
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"reflect"
+
+	"github.com/mbolis/genetta/layout"
+)
+
+var errUnbound = errors.New("real-valued crossover is not bound to any gene layout")
+
+// realValued operators decode the genes as floating point values, so they
+// need to be bound to the layout of the chromosome. They only blend its
+// floating point genes: children inherit any other gene from their parents.
+type realValued struct {
+	genes []layout.Gene
+}
+
+func (realValued) IsCompatible(chromosomeType reflect.Kind, flags uint) bool {
+	return chromosomeType == reflect.Float32 || chromosomeType == reflect.Float64
+}
+
+// blend sets each gene of the children to f applied to the same genes of the
+// parents.
+func (r realValued) blend(mom, dad, child1, child2 []byte, f func(g layout.Gene, x, y float64) (float64, float64)) error {
+	if r.genes == nil {
+		return errUnbound
+	}
+
+	copy(child1, mom)
+	copy(child2, dad)
+	for _, g := range r.genes {
+		c1, c2 := f(g, g.Float(mom), g.Float(dad))
+		g.SetFloat(child1, c1)
+		g.SetFloat(child2, c2)
+	}
+	return nil
+}
+
+type wholeArithmetic struct {
+	realValued
+
+	alpha float64
+}
+
+// WholeArithmetic sets each gene of the first child to alpha times the gene of
+// mom plus 1-alpha times the gene of dad, and the other way round for the
+// second child.
+func WholeArithmetic(alpha float64) Operator {
+	if alpha < 0 || alpha > 1 {
+		panic(fmt.Sprintf("invalid arithmetic crossover weight: %f", alpha)) // TODO
+	}
+	return wholeArithmetic{alpha: alpha}
+}
+
+func (a wholeArithmetic) Bind(l layout.Chromosome) Operator {
+	return wholeArithmetic{realValued{l.FloatGenes()}, a.alpha}
+}
+
+func (a wholeArithmetic) Crossover(mom, dad, child1, child2 []byte) error {
+	return a.blend(mom, dad, child1, child2, func(_ layout.Gene, x, y float64) (float64, float64) {
+		return a.alpha*x + (1-a.alpha)*y, (1-a.alpha)*x + a.alpha*y
+	})
+}
+
+type singleArithmetic struct {
+	realValued
+
+	alpha float64
+}
+
+// SingleArithmetic works like WholeArithmetic on a single gene picked at
+// random, copying the others from the parents.
+func SingleArithmetic(alpha float64) Operator {
+	if alpha < 0 || alpha > 1 {
+		panic(fmt.Sprintf("invalid arithmetic crossover weight: %f", alpha)) // TODO
+	}
+	return singleArithmetic{alpha: alpha}
+}
+
+func (a singleArithmetic) Bind(l layout.Chromosome) Operator {
+	return singleArithmetic{realValued{l.FloatGenes()}, a.alpha}
+}
+
+func (a singleArithmetic) Crossover(mom, dad, child1, child2 []byte) error {
+	if a.genes == nil {
+		return errUnbound
+	}
+
+	copy(child1, mom)
+	copy(child2, dad)
+	if len(a.genes) == 0 {
+		return nil
+	}
+
+	g := a.genes[rand.IntN(len(a.genes))]
+	x, y := g.Float(mom), g.Float(dad)
+	g.SetFloat(child1, a.alpha*x+(1-a.alpha)*y)
+	g.SetFloat(child2, (1-a.alpha)*x+a.alpha*y)
+	return nil
+}
+
+type blxAlpha struct {
+	realValued
+
+	alpha float64
+}
+
+// BLXAlpha draws each gene of each child uniformly from the interval spanned
+// by the genes of the parents, extended on both sides by alpha times its
+// length, and clamped to the bounds of the gene.
+func BLXAlpha(alpha float64) Operator {
+	if alpha < 0 {
+		panic(fmt.Sprintf("invalid BLX-α extension: %f", alpha)) // TODO
+	}
+	return blxAlpha{alpha: alpha}
+}
+
+func (b blxAlpha) Bind(l layout.Chromosome) Operator {
+	return blxAlpha{realValued{l.FloatGenes()}, b.alpha}
+}
+
+func (b blxAlpha) Crossover(mom, dad, child1, child2 []byte) error {
+	return b.blend(mom, dad, child1, child2, func(g layout.Gene, x, y float64) (float64, float64) {
+		lo, hi := min(x, y), max(x, y)
+		d := b.alpha * (hi - lo)
+		lo, hi = lo-d, hi+d
+
+		return g.Clamp(lo + rand.Float64()*(hi-lo)), g.Clamp(lo + rand.Float64()*(hi-lo))
+	})
+}
+
+type sbx struct {
+	realValued
+
+	eta float64
+}
+
+// SBX is the simulated binary crossover: each pair of genes of the children is
+// spread around the mean of the parents' genes by a random factor whose
+// distribution mimics single-point crossover on binary strings. The larger the
+// distribution index eta, the closer the children are to their parents.
+// Children are clamped to the bounds of the genes.
+func SBX(eta float64) Operator {
+	if eta < 0 {
+		panic(fmt.Sprintf("invalid SBX distribution index: %f", eta)) // TODO
+	}
+	return sbx{eta: eta}
+}
+
+func (s sbx) Bind(l layout.Chromosome) Operator {
+	return sbx{realValued{l.FloatGenes()}, s.eta}
+}
+
+func (s sbx) Crossover(mom, dad, child1, child2 []byte) error {
+	return s.blend(mom, dad, child1, child2, func(g layout.Gene, x, y float64) (float64, float64) {
+		u := rand.Float64()
+		var beta float64
+		if u <= 0.5 {
+			beta = math.Pow(2*u, 1/(s.eta+1))
+		} else {
+			beta = math.Pow(1/(2*(1-u)), 1/(s.eta+1))
+		}
+
+		c1 := 0.5 * ((1+beta)*x + (1-beta)*y)
+		c2 := 0.5 * ((1-beta)*x + (1+beta)*y)
+		return g.Clamp(c1), g.Clamp(c2)
+	})
+}
+
+type linear struct {
+	realValued
+}
+
+// Linear is Wright's linear crossover: it considers the midpoint of the
+// parents, and the points lying beyond each parent by half their distance, and
+// keeps two of them at random, discarding those falling out of the bounds of
+// the genes.
+func Linear() Operator {
+	return linear{}
+}
+
+func (l linear) Bind(c layout.Chromosome) Operator {
+	return linear{realValued{c.FloatGenes()}}
+}
+
+var linearWeights = [3][2]float64{
+	{0.5, 0.5},
+	{1.5, -0.5},
+	{-0.5, 1.5},
+}
+
+func (l linear) Crossover(mom, dad, child1, child2 []byte) error {
+	if l.genes == nil {
+		return errUnbound
+	}
+
+	// the midpoint always lies within bounds
+	var candidates [3]int
+	n := 1
+	for c := 1; c < len(linearWeights); c++ {
+		if l.inBounds(mom, dad, linearWeights[c]) {
+			candidates[n] = c
+			n++
+		}
+	}
+
+	rand.Shuffle(n, func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+	w1 := linearWeights[candidates[0]]
+	w2 := w1
+	if n > 1 {
+		w2 = linearWeights[candidates[1]]
+	}
+
+	copy(child1, mom)
+	copy(child2, dad)
+	for _, g := range l.genes {
+		x, y := g.Float(mom), g.Float(dad)
+		g.SetFloat(child1, w1[0]*x+w1[1]*y)
+		g.SetFloat(child2, w2[0]*x+w2[1]*y)
+	}
+	return nil
+}
+
+func (l linear) inBounds(mom, dad []byte, w [2]float64) bool {
+	for _, g := range l.genes {
+		v := w[0]*g.Float(mom) + w[1]*g.Float(dad)
+		if v < g.Min || v > g.Max {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,200 @@
+package crossover_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/mbolis/genetta/crossover"
+	"github.com/mbolis/genetta/layout"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// realLayout describes an unbounded float64 gene followed by a float32 gene
+// bounded within [0, 10]
+var realLayout = layout.Chromosome{
+	Genes: []layout.Gene{
+		{Offset: 0, Width: 64, Kind: reflect.Float64, Min: math.Inf(-1), Max: math.Inf(1)},
+		{Offset: 64, Width: 32, Kind: reflect.Float32, Min: 0, Max: 10},
+	},
+	Bytes: 12,
+}
+
+func realChromosome(x, y float64) []byte {
+	data := make([]byte, realLayout.Bytes)
+	realLayout.Genes[0].SetFloat(data, x)
+	realLayout.Genes[1].SetFloat(data, y)
+	return data
+}
+
+func realValues(data []byte) (x, y float64) {
+	return realLayout.Genes[0].Float(data), realLayout.Genes[1].Float(data)
+}
+
+func crossReal(t *testing.T, op crossover.Operator, mom, dad []byte) (child1, child2 []byte) {
+	t.Helper()
+
+	child1 = make([]byte, realLayout.Bytes)
+	child2 = make([]byte, realLayout.Bytes)
+	require.NoError(t, op.Crossover(mom, dad, child1, child2))
+	return
+}
+
+func TestRealCompatibility(t *testing.T) {
+	for _, op := range []crossover.Operator{
+		crossover.WholeArithmetic(0.5),
+		crossover.SingleArithmetic(0.5),
+		crossover.BLXAlpha(0.5),
+		crossover.SBX(2),
+		crossover.Linear(),
+	} {
+		assert.True(t, op.IsCompatible(reflect.Float32, 0))
+		assert.True(t, op.IsCompatible(reflect.Float64, 0))
+		assert.False(t, op.IsCompatible(reflect.Int, 0))
+
+		var child1, child2 [12]byte
+		assert.Error(t, op.Crossover(realChromosome(1, 1), realChromosome(2, 2), child1[:], child2[:]), "should fail when unbound")
+	}
+	assert.False(t, crossover.TwoPoints().IsCompatible(reflect.Float64, 0))
+}
+
+func TestWholeArithmetic(t *testing.T) {
+	op := crossover.Bind(crossover.WholeArithmetic(0.25), realLayout)
+
+	child1, child2 := crossReal(t, op, realChromosome(0, 4), realChromosome(8, 8))
+	x, y := realValues(child1)
+	assert.Equal(t, 6.0, x)
+	assert.Equal(t, 7.0, y)
+	x, y = realValues(child2)
+	assert.Equal(t, 2.0, x)
+	assert.Equal(t, 5.0, y)
+}
+
+func TestSingleArithmetic(t *testing.T) {
+	op := crossover.Bind(crossover.SingleArithmetic(0.5), realLayout)
+
+	var first, second int
+	for range repeats {
+		child1, _ := crossReal(t, op, realChromosome(0, 4), realChromosome(8, 8))
+		switch x, y := realValues(child1); {
+		case x == 4 && y == 4:
+			first++
+		case x == 0 && y == 6:
+			second++
+		default:
+			t.Fatalf("more than one gene was crossed: %v, %v", x, y)
+		}
+	}
+	assert.InEpsilon(t, repeats/2, first, 0.1)
+	assert.InEpsilon(t, repeats/2, second, 0.1)
+}
+
+func TestBLXAlpha(t *testing.T) {
+	op := crossover.Bind(crossover.BLXAlpha(0.5), realLayout)
+
+	var sum float64
+	for range repeats {
+		child1, child2 := crossReal(t, op, realChromosome(2, 2), realChromosome(6, 8))
+		for _, c := range [][]byte{child1, child2} {
+			x, y := realValues(c)
+			assert.GreaterOrEqual(t, x, 0.0)
+			assert.LessOrEqual(t, x, 8.0)
+			assert.GreaterOrEqual(t, y, 0.0, "should be clamped to the bounds")
+			assert.LessOrEqual(t, y, 10.0, "should be clamped to the bounds")
+			sum += x
+		}
+	}
+	assert.InDelta(t, 4, sum/(2*repeats), 0.05)
+}
+
+func TestSBX(t *testing.T) {
+	for _, eta := range []float64{1, 10} {
+		op := crossover.Bind(crossover.SBX(eta), realLayout)
+
+		var spread float64
+		for range repeats {
+			child1, child2 := crossReal(t, op, realChromosome(2, 2), realChromosome(6, 8))
+			x1, y1 := realValues(child1)
+			x2, y2 := realValues(child2)
+
+			assert.InDelta(t, 8, x1+x2, 1e-9, "children should be symmetric around the parents' mean")
+			assert.True(t, y1 >= 0 && y1 <= 10 && y2 >= 0 && y2 <= 10, "should be clamped to the bounds")
+			spread += math.Abs(x1 - x2)
+		}
+
+		// the children are spread by beta times the distance of the parents,
+		// beta having mean 4/3 with eta = 1, and about 1 with eta = 10
+		spread /= repeats
+		if eta == 1 {
+			assert.Greater(t, spread, 4.5)
+		} else {
+			assert.Less(t, spread, 4.5)
+		}
+	}
+}
+
+func TestLinear(t *testing.T) {
+	op := crossover.Bind(crossover.Linear(), realLayout)
+
+	t.Run("should pick two of the three candidates", func(t *testing.T) {
+		seen := map[float64]int{}
+		for range repeats {
+			child1, child2 := crossReal(t, op, realChromosome(2, 4), realChromosome(6, 6))
+			x1, _ := realValues(child1)
+			x2, _ := realValues(child2)
+			assert.NotEqual(t, x1, x2)
+			seen[x1]++
+			seen[x2]++
+		}
+		assert.Len(t, seen, 3)
+		for _, x := range []float64{4, 0, 8} {
+			assert.InEpsilon(t, 2*repeats/3, seen[x], 0.1)
+		}
+	})
+	t.Run("should discard candidates out of bounds", func(t *testing.T) {
+		for range repeats {
+			child1, child2 := crossReal(t, op, realChromosome(2, 1), realChromosome(6, 9))
+			for _, c := range [][]byte{child1, child2} {
+				_, y := realValues(c)
+				assert.True(t, y >= 0 && y <= 10)
+			}
+		}
+	})
+}
+
+func TestRealMixedGenes(t *testing.T) {
+	// a float64 gene followed by an int32 gene
+	l := layout.Chromosome{
+		Genes: []layout.Gene{
+			{Offset: 0, Width: 64, Kind: reflect.Float64, Min: 0, Max: 10},
+			{Offset: 64, Width: 32, Kind: reflect.Int32, Min: math.Inf(-1), Max: math.Inf(1)},
+		},
+		Bytes: 12,
+	}
+	chromosome := func(x float64, n uint64) []byte {
+		data := make([]byte, l.Bytes)
+		l.Genes[0].SetFloat(data, x)
+		l.Genes[1].SetUint(data, n)
+		return data
+	}
+
+	for _, op := range []crossover.Operator{
+		crossover.WholeArithmetic(0.5),
+		crossover.SingleArithmetic(0.5),
+		crossover.BLXAlpha(0.5),
+		crossover.SBX(2),
+		crossover.Linear(),
+	} {
+		op = crossover.Bind(op, l)
+		for range 100 {
+			child1, child2 := make([]byte, l.Bytes), make([]byte, l.Bytes)
+			require.NoError(t, op.Crossover(chromosome(2, 7), chromosome(8, 42), child1, child2))
+
+			assert.Equal(t, uint64(7), l.Genes[1].Uint(child1), "should inherit integer genes")
+			assert.Equal(t, uint64(42), l.Genes[1].Uint(child2), "should inherit integer genes")
+			x := l.Genes[0].Float(child1)
+			assert.True(t, x >= 0 && x <= 10, "%f out of bounds", x)
+		}
+	}
+}
@@ -1,38 +1,111 @@
 package genetta
 
 import (
+	"context"
 	"fmt"
+	"log"
 	"math"
+	"math/rand/v2"
+	"slices"
+	"time"
 
 	"github.com/mbolis/genetta/genotype"
+	"github.com/mbolis/genetta/internal/cache"
+	"github.com/mbolis/genetta/internal/workerpool"
 	"github.com/mbolis/genetta/model"
+	"github.com/mbolis/genetta/replacement"
+	"github.com/mbolis/genetta/scaling"
 	"github.com/mbolis/genetta/selection"
+	"github.com/mbolis/genetta/termination"
 )
 
 type GA[Phenotype any] interface {
-	Epoch() (Result[Phenotype], bool)
-	Epochs(int) (Result[Phenotype], bool)
+	Epoch() (Result[Phenotype], bool, error)
+	Epochs(int) (Result[Phenotype], bool, error)
+
+	// Run evolves the population for at most n generations, or indefinitely if
+	// n <= 0, until the target fitness is found or ctx is done. Cancellation is
+	// checked between generations and during fitness evaluation, and returns
+	// ctx.Err(). Whatever stops the run, the best individual found so far is
+	// returned, and the flag tells whether a termination criterion fired.
+	Run(ctx context.Context, n int) (Result[Phenotype], bool, error)
 
 	Generation() int
 	TargetFitness() float64
 	Elitism() (size, copies int)
+
+	// Close releases the worker goroutines held by the solver, if any.
+	Close()
 }
 
 type gaSolver[P any] struct {
 	schema       genotype.Schema[P]
 	population   model.Population[P]
+	offspring    model.Population[P]
+	survivors    model.Population[P]
+	evaluating   model.Population[P]
+	evaluated    bool
+	births       []int
+	scaled       []float64
 	breedingPool [][]byte
 	generation   int
+	evaluations  int
+	avoided      int
+	start        time.Time
+	best         Result[P]
 
 	fitnessFunc func(P) float64
+	cache       *cache.LRU
 	opts        options
+	stop        termination.Criterion
+	observers   []Observer[P]
+
+	ctx        context.Context
+	evaluators workerpool.Pool[evaluator[P]]
+	breeders   workerpool.Pool[breeder[P]]
+}
+
+type evaluator[P any] struct {
+	phenotype   P
+	ready       bool
+	aggregate   model.Aggregate
+	evaluations int
+	avoided     int
+}
+
+func (e *evaluator[P]) Reset() {
+	e.aggregate = model.Aggregate{}
+	e.evaluations = 0
+	e.avoided = 0
+}
+
+type breeder[P any] struct {
+	schema genotype.Schema[P]
+	ready  bool
+	spare  []byte
+	err    error
+}
+
+func (b *breeder[P]) Reset() {
+	b.err = nil
 }
 
 type options struct {
 	populationSize int
+	parallelism    int
+	cacheSize      int
+	direction      model.Direction
 	targetFitness  *float64
+	termination    termination.Criterion
+	errorPolicy    ErrorPolicy
+	observers      []any
 	selectionOp    selection.Operator
-	// scalingOp scaling.Operator
+	scalingOp      scaling.Operator
+	replacement    replacement.Strategy
+	steadyState    struct {
+		children int
+		policy   SteadyStatePolicy
+	}
 	elite struct {
 		size   int
 		copies int
@@ -43,18 +116,129 @@ type options struct {
 
 type Option func(*options) error
 
+// WithMinimize makes the solver look for the lowest fitness values rather than
+// for the highest ones. Statistics, elitism, selection, scaling and
+// termination criteria all follow suit.
+func WithMinimize() func(*options) error {
+	return func(o *options) error {
+		o.direction = model.Minimize
+		return nil
+	}
+}
+
+// WithTargetFitness stops the run as soon as the best fitness reaches f.
 func WithTargetFitness(f float64) func(*options) error {
 	return func(o *options) error {
 		o.targetFitness = &f
 		return nil
 	}
 }
+
+// WithTermination stops the run as soon as c fires. It can be combined with
+// WithTargetFitness, in which case the first one to fire stops the run.
+func WithTermination(c termination.Criterion) func(*options) error {
+	return func(o *options) error {
+		if c == nil {
+			return fmt.Errorf("termination criterion must not be nil")
+		}
+
+		o.termination = c
+		return nil
+	}
+}
+
+// ErrorPolicy tells the solver what to do when a genetic operator fails.
+// Whatever the policy, a failed scaling falls back to raw fitness values, and
+// a failed selection falls back to random selection, unless aborting.
+type ErrorPolicy int
+
+const (
+	// AbortOnError stops the run and returns the error, leaving the current
+	// population untouched.
+	AbortOnError ErrorPolicy = iota
+	// SkipOnError copies the parents over the children of a failed pair.
+	SkipOnError
+	// LogOnError logs the error and keeps the children as the operator left
+	// them.
+	LogOnError
+)
+
+func WithErrorPolicy(p ErrorPolicy) func(*options) error {
+	return func(o *options) error {
+		switch p {
+		case AbortOnError, SkipOnError, LogOnError:
+		default:
+			return fmt.Errorf("invalid error policy: %d", p)
+		}
+
+		o.errorPolicy = p
+		return nil
+	}
+}
+
 func WithSelection(op selection.Operator) func(*options) error {
 	return func(o *options) error {
 		o.selectionOp = op
 		return nil
 	}
 }
+
+// WithScaling scales the fitness values before selection. Scaling works on a
+// copy of the fitness values: results, statistics, termination criteria and
+// elitism all see the raw ones.
+func WithScaling(op scaling.Operator) func(*options) error {
+	return func(o *options) error {
+		o.scalingOp = op
+		return nil
+	}
+}
+
+// WithReplacement makes the solver evaluate the offspring as soon as they are
+// bred, and let s pick the survivors among parents and offspring. By default,
+// the offspring replace the whole population.
+func WithReplacement(s replacement.Strategy) func(*options) error {
+	return func(o *options) error {
+		o.replacement = s
+		return nil
+	}
+}
+
+// SteadyStatePolicy tells a steady-state solver which individual each new
+// child replaces.
+type SteadyStatePolicy int
+
+const (
+	// ReplaceWorst replaces the worst individual of the population.
+	ReplaceWorst SteadyStatePolicy = iota
+	// ReplaceRandom replaces an individual picked at random.
+	ReplaceRandom
+	// ReplaceOldest replaces the individual that has been in the population
+	// for the longest time.
+	ReplaceOldest
+)
+
+// WithSteadyState makes the solver breed just a few children at a time, and
+// evaluate only them: each generation selects the parents, breeds the given
+// number of children, and puts each of them in place of an individual of the
+// population, picked according to policy. The children replace their victims
+// whatever their fitness.
+func WithSteadyState(children int, policy SteadyStatePolicy) func(*options) error {
+	return func(o *options) error {
+		if children <= 0 {
+			return fmt.Errorf("steady-state children must be > 0, was %d", children)
+		}
+		switch policy {
+		case ReplaceWorst, ReplaceRandom, ReplaceOldest:
+		default:
+			return fmt.Errorf("invalid steady-state policy: %d", policy)
+		}
+
+		o.steadyState.children = children
+		o.steadyState.policy = policy
+		return nil
+	}
+}
+
 func WithElitism(size, copies int) func(*options) error {
 	return func(o *options) error {
 		if size <= 0 || copies <= 0 {
@@ -68,6 +252,35 @@ func WithElitism(size, copies int) func(*options) error {
 	}
 }
 
+// WithFitnessCache remembers the fitness of the last size distinct genotypes
+// evaluated, so that unchanged individuals, like elites or children that were
+// neither crossed over nor mutated, are not evaluated again. It should only be
+// used with deterministic fitness functions.
+func WithFitnessCache(size int) func(*options) error {
+	return func(o *options) error {
+		if size <= 0 {
+			return fmt.Errorf("fitness cache size must be > 0, was %d", size)
+		}
+
+		o.cacheSize = size
+		return nil
+	}
+}
+
+// WithParallelism spreads fitness evaluation and breeding over n goroutines;
+// each one decodes into its own phenotype buffer and uses its own clone of the
+// schema operators.
+func WithParallelism(n int) func(*options) error {
+	return func(o *options) error {
+		if n <= 0 {
+			return fmt.Errorf("parallelism must be > 0, was %d", n)
+		}
+
+		o.parallelism = n
+		return nil
+	}
+}
+
 func NewSolver[P any](genotype genotype.Schema[P], fitnessFunc func(P) float64, populationSize int, opts ...Option) (GA[P], error) {
 	if populationSize <= 0 {
 		return nil, fmt.Errorf("population size must be > 0, was %d", populationSize)
@@ -75,6 +288,7 @@ func NewSolver[P any](genotype genotype.Schema[P], fitnessFunc func(P) float64,
 
 	o := options{
 		populationSize: populationSize,
+		parallelism:    1,
 	}
 	for _, opt := range opts {
 		err := opt(&o)
@@ -83,14 +297,84 @@ func NewSolver[P any](genotype genotype.Schema[P], fitnessFunc func(P) float64,
 		}
 	}
 
-	return &gaSolver[P]{
+	observers, err := observersFor[P](o.observers)
+	if err != nil {
+		return nil, err
+	}
+
+	nOffspring, nParents := populationSize, populationSize
+	if o.steadyState.children > 0 {
+		if o.replacement != nil || o.elite.len > 0 {
+			return nil, fmt.Errorf("steady-state mode does not support replacement strategies nor elitism")
+		}
+		nOffspring = o.steadyState.children
+		nParents = nOffspring + nOffspring%2
+	} else if o.replacement != nil {
+		if err := replacement.Validate(o.replacement, populationSize); err != nil {
+			return nil, err
+		}
+		nOffspring = o.replacement.Offspring(populationSize)
+		if nOffspring <= 0 {
+			return nil, fmt.Errorf("offspring size must be > 0, was %d", nOffspring)
+		}
+		nParents = nOffspring
+	}
+	if o.elite.size > populationSize || o.elite.len > nParents {
+		return nil, fmt.Errorf("elite size/copies %d/%d do not fit a population of %d and a breeding pool of %d",
+			o.elite.size, o.elite.copies, populationSize, nParents)
+	}
+
+	ga := &gaSolver[P]{
 		schema:       genotype,
 		fitnessFunc:  fitnessFunc,
 		opts:         o,
 		population:   model.New(genotype, populationSize),
-		breedingPool: make([][]byte, populationSize),
+		offspring:    model.New(genotype, nOffspring),
+		breedingPool: make([][]byte, nParents),
 		generation:   1,
-	}, nil
+		observers:    observers,
+		ctx:          context.Background(),
+	}
+
+	ga.population.SetDirection(o.direction)
+	ga.offspring.SetDirection(o.direction)
+	if o.replacement != nil {
+		ga.survivors = model.New(genotype, populationSize)
+		ga.survivors.SetDirection(o.direction)
+	}
+	if o.cacheSize > 0 {
+		ga.cache = cache.NewLRU(o.cacheSize)
+	}
+	if o.steadyState.policy == ReplaceOldest {
+		ga.births = make([]int, populationSize)
+	}
+
+	ga.stop = o.termination
+	if o.targetFitness != nil {
+		target := termination.FitnessReached(*o.targetFitness, 0)
+		if ga.stop == nil {
+			ga.stop = target
+		} else {
+			ga.stop = termination.Or(target, ga.stop)
+		}
+	}
+
+	if o.parallelism > 1 {
+		bufferSize := max(populationSize, nOffspring)
+		pool, err := workerpool.New(o.parallelism, bufferSize, ga.evaluate)
+		if err != nil {
+			return nil, err
+		}
+		ga.evaluators = pool
+
+		breeders, err := workerpool.New(o.parallelism, bufferSize, ga.breed)
+		if err != nil {
+			return nil, err
+		}
+		ga.breeders = breeders
+	}
+
+	return ga, nil
 }
 
 func (ga gaSolver[P]) Generation() int {
@@ -106,72 +390,356 @@ func (ga gaSolver[P]) Elitism() (size, copy int) {
 	return ga.opts.elite.size, ga.opts.elite.copies
 }
 
+func (ga *gaSolver[P]) Close() {
+	if ga.evaluators != nil {
+		ga.evaluators.Close()
+		ga.evaluators = nil
+	}
+	if ga.breeders != nil {
+		ga.breeders.Close()
+		ga.breeders = nil
+	}
+}
+
+// Result is a snapshot of an individual: it is not affected by the evolution
+// of the population after it was taken. The zero Result holds no individual.
 type Result[P any] struct {
-	population model.Population[P]
-	index      int
+	schema     genotype.Schema[P]
+	genotype   []byte
+	fitness    float64
+	generation int
+	stoppedBy  termination.Criterion
 }
 
-func (ga *gaSolver[P]) Epoch() (fittest Result[P], found bool) {
+func (ga *gaSolver[P]) snapshot(i int) Result[P] {
+	return Result[P]{
+		schema:     ga.schema,
+		genotype:   slices.Clone(ga.population.Genotype(i)),
+		fitness:    ga.population.Fitness(i),
+		generation: ga.generation,
+	}
+}
+
+// Phenotype decodes the genotype of the individual into a new phenotype.
+func (r Result[P]) Phenotype() (phenotype P) {
+	if r.genotype == nil {
+		return
+	}
+
+	phenotype = r.schema.Init()
+	r.schema.Decode(&phenotype, r.genotype)
+	return
+}
+
+func (r Result[P]) Fitness() float64 {
+	return r.fitness
+}
+
+// Genotype returns a copy of the genotype of the individual.
+func (r Result[P]) Genotype() []byte {
+	return slices.Clone(r.genotype)
+}
+
+// Generation returns the generation in which the individual was evaluated.
+func (r Result[P]) Generation() int {
+	return r.generation
+}
+
+// StoppedBy returns the termination criterion that stopped the run, or nil.
+func (r Result[P]) StoppedBy() termination.Criterion {
+	return r.stoppedBy
+}
+
+func (ga *gaSolver[P]) Epoch() (fittest Result[P], found bool, err error) {
 	return ga.Epochs(1)
 }
 
-func (ga *gaSolver[P]) Epochs(n int) (fittest Result[P], found bool) {
-	for range n {
-		fittest, found = ga.calculateFitnessScores()
-		if found {
-			break
+func (ga *gaSolver[P]) Epochs(n int) (fittest Result[P], found bool, err error) {
+	if n <= 0 {
+		return
+	}
+	return ga.Run(context.Background(), n)
+}
+
+func (ga *gaSolver[P]) Run(ctx context.Context, n int) (fittest Result[P], found bool, err error) {
+	ga.ctx = ctx
+	defer func() { ga.ctx = context.Background() }()
+
+	for i := 0; n <= 0 || i < n; i++ {
+		fittest, found, err = ga.calculateFitnessScores()
+		if found || err != nil {
+			return
+		}
+		if err = ctx.Err(); err != nil {
+			return
+		}
+		if err = ga.nextGeneration(); err != nil {
+			return
 		}
-		ga.nextGeneration()
 	}
 	return
 }
 
-func (ga *gaSolver[P]) calculateFitnessScores() (Result[P], bool) {
-	ga.population.Reset()
+func (ga *gaSolver[P]) calculateFitnessScores() (Result[P], bool, error) {
+	if ga.start.IsZero() {
+		ga.start = time.Now()
+	}
+	if !ga.evaluated {
+		ga.evaluateAll(&ga.population)
+		ga.evaluated = ga.ctx.Err() == nil
+	}
+
+	fittest, bestFitness := ga.population.Fittest()
+	if fittest < 0 {
+		return ga.best, false, ga.ctx.Err()
+	}
+
+	result := ga.snapshot(fittest)
+	newBest := ga.best.genotype == nil || ga.population.Direction().Better(bestFitness, ga.best.fitness)
+	if newBest {
+		ga.best = result
+	}
+
+	if err := ga.ctx.Err(); err != nil {
+		return ga.best, false, err
+	}
+
+	state := termination.State{
+		Generation:  ga.generation,
+		Evaluations: ga.evaluations,
+		Elapsed:     time.Since(ga.start),
+		Stats:       ga.population.Stats(),
+		Genomes:     ga.population.Genomes,
+	}
+	if ga.stop != nil {
+		result.stoppedBy = ga.stop.Check(state)
+	}
+
+	if len(ga.observers) > 0 {
+		ga.notify(Event[P]{
+			Generation:         state.Generation,
+			Evaluations:        state.Evaluations,
+			EvaluationsAvoided: ga.avoided,
+			Elapsed:            state.Elapsed,
+			Stats:              state.Stats,
+			Best:               result,
+		}, newBest)
+	}
+
+	best := ga.best
+	best.stoppedBy = result.stoppedBy
+	return best, best.stoppedBy != nil, nil
+}
+
+// evaluateAll computes the fitness of all individuals of p, stopping early if
+// the context is done.
+func (ga *gaSolver[P]) evaluateAll(p *model.Population[P]) {
+	p.Reset()
+	ga.evaluating = *p
 
-	phenotype := ga.schema.Init()
-	for i := range ga.population.NIndividuals() { // TODO parallelize
-		ga.population.Decode(i, &phenotype)
-		ga.population.SetFitness(i, ga.calculateFitness(phenotype))
+	if ga.evaluators == nil {
+		var e evaluator[P]
+		for i := range p.NIndividuals() {
+			ga.evaluate(&e, i)
+		}
+		p.Merge(e.aggregate)
+		ga.evaluations += e.evaluations
+		ga.avoided += e.avoided
+	} else {
+		dispatch(ga.evaluators, p.NIndividuals(), 1)
+		for _, e := range ga.evaluators.All() {
+			p.Merge(e.aggregate)
+			ga.evaluations += e.evaluations
+			ga.avoided += e.avoided
+		}
+	}
+}
+
+func (ga *gaSolver[P]) evaluate(e *evaluator[P], i int) {
+	if ga.ctx.Err() != nil {
+		return
+	}
+	if !e.ready {
+		e.phenotype = ga.schema.Init()
+		e.ready = true
 	}
 
-	fittest, maxFitness := ga.population.Fittest()
-	result := Result[P]{ga.population, fittest}
-	if ga.opts.targetFitness != nil && maxFitness == *ga.opts.targetFitness {
-		return result, true
+	genotype := ga.evaluating.Genotype(i)
+	fitness, cached := 0.0, false
+	if ga.cache != nil {
+		fitness, cached = ga.cache.Get(genotype)
 	}
-	// TODO scale fitness
-	return result, false
+
+	if cached {
+		e.avoided++
+	} else {
+		ga.evaluating.Decode(i, &e.phenotype)
+		fitness = ga.calculateFitness(e.phenotype)
+		e.evaluations++
+		if ga.cache != nil {
+			ga.cache.Put(genotype, fitness)
+		}
+	}
+
+	ga.evaluating.StoreFitness(i, fitness)
+	e.aggregate.Add(i, fitness)
 }
 
 func (ga *gaSolver[P]) calculateFitness(phenotype P) float64 {
 	return ga.fitnessFunc(phenotype)
 }
 
-func (ga *gaSolver[P]) nextGeneration() {
+func (ga *gaSolver[P]) nextGeneration() error {
 	// TODO elite
 
-	ga.selectBreedingPool()
-	for i := 0; i < ga.population.NIndividuals(); i += 2 { // TODO parallelize
-		mom := ga.breedingPool[i]
-		dad := ga.breedingPool[i+1]
-
-		child1 := ga.population.Genotype(i)
-		child2 := ga.population.Genotype(i + 1)
+	if err := ga.selectBreedingPool(); err != nil {
+		return err
+	}
 
-		if err := ga.schema.Crossover(mom, dad, child1, child2); err != nil {
-			// TODO
+	if ga.breeders == nil {
+		b := breeder[P]{schema: ga.schema, ready: true}
+		for i := 0; i < ga.offspring.NIndividuals(); i += 2 {
+			ga.breed(&b, i)
 		}
-		if err := ga.schema.Mutate(child1, child2); err != nil {
-			// TODO
+		if b.err != nil {
+			return b.err
+		}
+	} else {
+		dispatch(ga.breeders, ga.offspring.NIndividuals(), 2)
+		for _, b := range ga.breeders.All() {
+			if b.err != nil {
+				return b.err
+			}
 		}
-		// TODO enforce constraints...
 	}
 
+	if ga.opts.steadyState.children > 0 {
+		return ga.replaceSteadyState()
+	}
+	if ga.opts.replacement == nil {
+		ga.population, ga.offspring = ga.offspring, ga.population
+		ga.evaluated = false
+		ga.generation++
+		return nil
+	}
+
+	ga.evaluateAll(&ga.offspring)
+	if err := ga.ctx.Err(); err != nil {
+		return err
+	}
+
+	err := ga.opts.replacement.Replace(ga.population.Genomes, ga.offspring.Genomes, &ga.survivors.Genomes)
+	if err != nil {
+		return fmt.Errorf("replacement failed: %w", err)
+	}
+
+	ga.population, ga.survivors = ga.survivors, ga.population
+	ga.evaluated = true
 	ga.generation++
+	return nil
+}
+
+// replaceSteadyState evaluates the offspring, and puts each of them in place
+// of the individual picked by the steady-state policy.
+func (ga *gaSolver[P]) replaceSteadyState() error {
+	ga.evaluateAll(&ga.offspring)
+	if err := ga.ctx.Err(); err != nil {
+		return err
+	}
+
+	ga.generation++
+	for i := range ga.offspring.NIndividuals() {
+		victim := ga.pickVictim()
+		copy(ga.population.Genotype(victim), ga.offspring.Genotype(i))
+		ga.population.SetFitness(victim, ga.offspring.Fitness(i))
+		if ga.births != nil {
+			ga.births[victim] = ga.generation
+		}
+	}
+	return nil
+}
+
+func (ga *gaSolver[P]) pickVictim() int {
+	switch ga.opts.steadyState.policy {
+	case ReplaceRandom:
+		return rand.IntN(ga.population.NIndividuals())
+	case ReplaceOldest:
+		oldest := 0
+		for i, birth := range ga.births {
+			if birth < ga.births[oldest] {
+				oldest = i
+			}
+		}
+		return oldest
+	}
+	worst, _ := ga.population.Worst()
+	return worst
+}
+
+// breed fills offspring i and i+1 from the parents at the same positions of
+// the breeding pool. With an odd number of parents, the last one is paired
+// with the first one; with an odd number of offspring, the second child is
+// discarded.
+func (ga *gaSolver[P]) breed(b *breeder[P], i int) {
+	if !b.ready {
+		b.schema = ga.schema.Clone()
+		b.ready = true
+	}
+
+	mom := ga.breedingPool[i]
+	dad := ga.breedingPool[0]
+	if i+1 < len(ga.breedingPool) {
+		dad = ga.breedingPool[i+1]
+	}
+
+	child1 := ga.offspring.Genotype(i)
+	var child2 []byte
+	if i+1 < ga.offspring.NIndividuals() {
+		child2 = ga.offspring.Genotype(i + 1)
+	} else {
+		if b.spare == nil {
+			b.spare = make([]byte, ga.schema.Size())
+		}
+		child2 = b.spare
+	}
+
+	err := b.schema.Crossover(mom, dad, child1, child2)
+	if err != nil {
+		err = fmt.Errorf("crossover failed: %w", err)
+	} else if err = b.schema.Mutate(child1, child2); err != nil {
+		err = fmt.Errorf("mutation failed: %w", err)
+	}
+	// TODO enforce constraints...
+
+	if err == nil {
+		return
+	}
+	switch ga.opts.errorPolicy {
+	case AbortOnError:
+		if b.err == nil {
+			b.err = err
+		}
+	case SkipOnError:
+		copy(child1, mom)
+		copy(child2, dad)
+	case LogOnError:
+		log.Printf("WARN: generation %d: %v", ga.generation, err)
+	}
 }
 
-func (ga *gaSolver[P]) selectBreedingPool() {
+// dispatch offers the inputs 0, step, 2*step... below n to the pool, and
+// waits for them to be processed.
+func dispatch[W any](pool workerpool.Pool[W], n, step int) {
+	for i := 0; i < n; i += step {
+		pool.Offer(i)
+	}
+	if pool.Status() == workerpool.StatusIdle {
+		pool.Resume()
+	}
+	pool.Wait()
+}
+
+func (ga *gaSolver[P]) selectBreedingPool() error {
 	var pos int
 	if ga.opts.elite.len > 0 {
 		ga.population.SortByFitnessDesc()
@@ -184,9 +752,39 @@ func (ga *gaSolver[P]) selectBreedingPool() {
 		}
 	}
 
-	if err := ga.opts.selectionOp.SelectInto(ga.population.Genomes, ga.breedingPool[pos:]); err != nil {
-		// TODO
+	genomes := ga.population.Genomes
+	if ga.opts.scalingOp != nil {
+		if ga.scaled == nil {
+			ga.scaled = make([]float64, genomes.NIndividuals())
+		}
+		genomes = genomes.Detach(ga.scaled)
+
+		err := ga.opts.scalingOp.Scale(&genomes, ga.generation)
+		if err != nil {
+			err = fmt.Errorf("scaling failed: %w", err)
+			switch ga.opts.errorPolicy {
+			case AbortOnError:
+				return err
+			case LogOnError:
+				log.Printf("WARN: generation %d: %v", ga.generation, err)
+			}
+			genomes = ga.population.Genomes
+		}
+	}
+
+	err := ga.opts.selectionOp.SelectInto(genomes, ga.breedingPool[pos:])
+	if err == nil {
+		return nil
+	}
+
+	err = fmt.Errorf("selection failed: %w", err)
+	switch ga.opts.errorPolicy {
+	case AbortOnError:
+		return err
+	case LogOnError:
+		log.Printf("WARN: generation %d: %v", ga.generation, err)
 	}
+	return selection.Random().SelectInto(genomes, ga.breedingPool[pos:])
 }
 
 // type Population[P any] struct {
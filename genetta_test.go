@@ -0,0 +1,637 @@
+package genetta
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mbolis/genetta/crossover"
+	"github.com/mbolis/genetta/genotype"
+	"github.com/mbolis/genetta/replacement"
+	"github.com/mbolis/genetta/scaling"
+	"github.com/mbolis/genetta/selection"
+	"github.com/mbolis/genetta/termination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sumOfGenes(ph []uint8) (sum float64) {
+	for _, v := range ph {
+		sum += float64(v)
+	}
+	return
+}
+
+func TestParallelFitness(t *testing.T) {
+	for _, n := range []int{1, 4} {
+		solver, err := NewSolver(genotype.Binary[uint8](8, 16), sumOfGenes, 100,
+			WithSelection(selection.Random()),
+			WithParallelism(n),
+		)
+		require.NoError(t, err)
+		defer solver.Close()
+
+		ga := solver.(*gaSolver[[]uint8])
+		for range 3 {
+			_, _, err := ga.calculateFitnessScores()
+			require.NoError(t, err)
+
+			var total float64
+			minFitness, maxFitness := ga.population.Fitness(0), ga.population.Fitness(0)
+			for i := range ga.population.NIndividuals() {
+				phenotype := ga.schema.Init()
+				ga.population.Decode(i, &phenotype)
+
+				f := ga.population.Fitness(i)
+				assert.Equal(t, sumOfGenes(phenotype), f)
+				total += f
+				minFitness = min(minFitness, f)
+				maxFitness = max(maxFitness, f)
+			}
+
+			stats := ga.population.Stats()
+			assert.Equal(t, total, stats.TotalFitness)
+			assert.Equal(t, minFitness, stats.MinFitness)
+			assert.Equal(t, maxFitness, stats.MaxFitness)
+
+			require.NoError(t, ga.nextGeneration())
+		}
+	}
+}
+
+func TestParallelBreeding(t *testing.T) {
+	schema, err := genotype.Build(func(bind genotype.BindFunc, ph *[]uint8) (s genotype.Spec) {
+		s.IntChromosome(bind(ph).Bits(8).Len(16)).
+			Crossover(crossover.Probability(0, crossover.TwoPoints()))
+		return
+	})
+	require.NoError(t, err)
+
+	for _, n := range []int{1, 4} {
+		solver, err := NewSolver(schema, sumOfGenes, 101,
+			WithSelection(selection.Random()),
+			WithParallelism(n),
+		)
+		require.NoError(t, err)
+		defer solver.Close()
+
+		ga := solver.(*gaSolver[[]uint8])
+		for range 3 {
+			parents := make(map[string]bool)
+			for i := range ga.population.NIndividuals() {
+				parents[string(ga.population.Genotype(i))] = true
+			}
+
+			_, _, err := ga.calculateFitnessScores()
+			require.NoError(t, err)
+			require.NoError(t, ga.nextGeneration())
+
+			for i := range ga.population.NIndividuals() {
+				assert.True(t, parents[string(ga.population.Genotype(i))], "offspring should be copies of their parents")
+			}
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Run("should stop on cancellation during fitness evaluation", func(t *testing.T) {
+		for _, n := range []int{1, 4} {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var evaluations atomic.Int32
+			fitness := func(ph []uint8) float64 {
+				if evaluations.Add(1) == 150 {
+					cancel()
+				}
+				return sumOfGenes(ph)
+			}
+
+			solver, err := NewSolver(genotype.Binary[uint8](8, 16), fitness, 100,
+				WithSelection(selection.Random()),
+				WithParallelism(n),
+			)
+			require.NoError(t, err)
+			defer solver.Close()
+
+			fittest, found, err := solver.Run(ctx, 0)
+			assert.ErrorIs(t, err, context.Canceled)
+			assert.False(t, found)
+			assert.NotNil(t, fittest.Genotype())
+			assert.Equal(t, sumOfGenes(fittest.Phenotype()), fittest.Fitness())
+			assert.Equal(t, 2, solver.Generation())
+			assert.Less(t, int(evaluations.Load()), 200)
+		}
+	})
+
+	t.Run("should stop on deadline between generations", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		solver, err := NewSolver(genotype.Binary[uint8](8, 16), sumOfGenes, 10,
+			WithSelection(selection.Random()),
+		)
+		require.NoError(t, err)
+		defer solver.Close()
+
+		_, found, err := solver.Run(ctx, 0)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.False(t, found)
+		assert.Greater(t, solver.Generation(), 1)
+	})
+
+	for name, opts := range map[string][]Option{
+		"generation budget":     nil,
+		"termination criterion": {WithTermination(termination.MaxGenerations(20))},
+	} {
+		t.Run("should return the best so far when stopped by the "+name, func(t *testing.T) {
+			var bestFitness float64
+			opts = append(opts,
+				WithSelection(selection.Random()),
+				WithObserver(ObserverFunc[[]uint8](func(e Event[[]uint8]) {
+					bestFitness = max(bestFitness, e.Stats.BestFitness)
+				})),
+			)
+			solver, err := NewSolver(genotype.Binary[uint8](8, 16), sumOfGenes, 10, opts...)
+			require.NoError(t, err)
+
+			fittest, _, err := solver.Epochs(30)
+			require.NoError(t, err)
+			assert.Equal(t, bestFitness, fittest.Fitness())
+			assert.Equal(t, sumOfGenes(fittest.Phenotype()), fittest.Fitness())
+		})
+	}
+}
+
+func TestErrorPolicy(t *testing.T) {
+	schema, err := genotype.Build(func(bind genotype.BindFunc, ph *[]uint8) (s genotype.Spec) {
+		s.IntChromosome(bind(ph).Bits(8).Len(2)).
+			Crossover(crossover.KPoints(16))
+		return
+	})
+	require.NoError(t, err)
+
+	newSolver := func(policy ErrorPolicy) *gaSolver[[]uint8] {
+		solver, err := NewSolver(schema, sumOfGenes, 10,
+			WithSelection(selection.Random()),
+			WithErrorPolicy(policy),
+		)
+		require.NoError(t, err)
+		return solver.(*gaSolver[[]uint8])
+	}
+
+	t.Run("should abort on operator errors", func(t *testing.T) {
+		ga := newSolver(AbortOnError)
+
+		_, _, err := ga.Epoch()
+		assert.ErrorContains(t, err, "crossover failed")
+		assert.Equal(t, 1, ga.Generation())
+	})
+
+	t.Run("should copy parents on operator errors", func(t *testing.T) {
+		ga := newSolver(SkipOnError)
+
+		parents := make(map[string]bool)
+		for i := range ga.population.NIndividuals() {
+			parents[string(ga.population.Genotype(i))] = true
+		}
+
+		_, _, err := ga.Epoch()
+		assert.NoError(t, err)
+		assert.Equal(t, 2, ga.Generation())
+		for i := range ga.population.NIndividuals() {
+			assert.True(t, parents[string(ga.population.Genotype(i))], "offspring should be copies of their parents")
+		}
+	})
+
+	t.Run("should log operator errors and continue", func(t *testing.T) {
+		var buf bytes.Buffer
+		log.SetOutput(&buf)
+		defer log.SetOutput(os.Stderr)
+
+		ga := newSolver(LogOnError)
+
+		_, _, err := ga.Epoch()
+		assert.NoError(t, err)
+		assert.Equal(t, 2, ga.Generation())
+		assert.Contains(t, buf.String(), "crossover failed")
+	})
+}
+
+func TestElitismWithRouletteWheel(t *testing.T) {
+	offset := func(ph []uint8) float64 { return sumOfGenes(ph) - 500 }
+	for _, minimize := range []bool{false, true} {
+		t.Run(fmt.Sprintf("should select from a sorted population without errors (minimize: %v)", minimize), func(t *testing.T) {
+			opts := []Option{
+				WithSelection(selection.RouletteWheel()),
+				WithElitism(2, 1),
+			}
+			if minimize {
+				opts = append(opts, WithMinimize())
+			}
+
+			for range 20 {
+				solver, err := NewSolver(genotype.Binary[uint8](8, 4), offset, 20, opts...)
+				require.NoError(t, err)
+
+				_, _, err = solver.Epochs(50)
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTermination(t *testing.T) {
+	solver, err := NewSolver(genotype.Binary[uint8](8, 16), sumOfGenes, 10,
+		WithSelection(selection.Random()),
+		WithTargetFitness(math.Inf(1)),
+		WithTermination(termination.Or(
+			termination.MaxEvaluations(1000),
+			termination.MaxGenerations(5),
+		)),
+	)
+	require.NoError(t, err)
+
+	fittest, found, err := solver.Run(context.Background(), 0)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, termination.MaxGenerations(5), fittest.StoppedBy())
+	assert.Equal(t, 5, solver.Generation())
+}
+
+type recorder struct {
+	generations []int
+	newBests    []float64
+	stoppedBy   termination.Criterion
+}
+
+func (r *recorder) OnGeneration(e Event[[]uint8]) {
+	r.generations = append(r.generations, e.Generation)
+}
+func (r *recorder) OnNewBest(e Event[[]uint8]) {
+	r.newBests = append(r.newBests, e.Stats.BestFitness)
+}
+func (r *recorder) OnTermination(e Event[[]uint8], c termination.Criterion) {
+	r.stoppedBy = c
+}
+
+func TestObserver(t *testing.T) {
+	t.Run("should notify generations, new bests and termination", func(t *testing.T) {
+		var r recorder
+		solver, err := NewSolver(genotype.Binary[uint8](8, 16), sumOfGenes, 10,
+			WithSelection(selection.Random()),
+			WithTermination(termination.MaxGenerations(5)),
+			WithObserver[[]uint8](&r),
+		)
+		require.NoError(t, err)
+
+		_, _, err = solver.Run(context.Background(), 0)
+		assert.NoError(t, err)
+
+		assert.Equal(t, []int{1, 2, 3, 4, 5}, r.generations)
+		assert.NotEmpty(t, r.newBests)
+		assert.IsIncreasing(t, r.newBests)
+		assert.Equal(t, termination.MaxGenerations(5), r.stoppedBy)
+	})
+
+	t.Run("should reject observers of other phenotypes", func(t *testing.T) {
+		_, err := NewSolver(genotype.Binary[uint8](8, 16), sumOfGenes, 10,
+			WithSelection(selection.Random()),
+			WithObserver(ObserverFunc[[]int](func(Event[[]int]) {})),
+		)
+		assert.Error(t, err)
+	})
+}
+
+func TestResult(t *testing.T) {
+	solver, err := NewSolver(genotype.Binary[uint8](8, 16), sumOfGenes, 10,
+		WithSelection(selection.Random()),
+	)
+	require.NoError(t, err)
+
+	fittest, _, err := solver.Epoch()
+	require.NoError(t, err)
+
+	phenotype := fittest.Phenotype()
+	genes := fittest.Genotype()
+	assert.Equal(t, 1, fittest.Generation())
+	assert.Len(t, phenotype, 16)
+	assert.Equal(t, sumOfGenes(phenotype), fittest.Fitness())
+
+	genes[0]++
+	assert.NotEqual(t, genes, fittest.Genotype(), "genotype should be a copy")
+	genes[0]--
+
+	_, _, err = solver.Epochs(10)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, fittest.Generation())
+	assert.Equal(t, phenotype, fittest.Phenotype())
+	assert.Equal(t, genes, fittest.Genotype())
+}
+
+func TestStructPhenotype(t *testing.T) {
+	type phenotype struct {
+		Gains  []uint8 `genetta:"len=4"`
+		Levels []int8  `genetta:"chromosome=levels,len=3,oneof=-1|0|1"`
+	}
+	fitness := func(ph phenotype) (sum float64) {
+		for _, v := range ph.Gains {
+			sum += float64(v)
+		}
+		for _, v := range ph.Levels {
+			sum += float64(v)
+		}
+		return
+	}
+
+	schema, err := genotype.FromStruct[phenotype]()
+	require.NoError(t, err)
+	solver, err := NewSolver(schema, fitness, 10, WithSelection(selection.Tournament(2)))
+	require.NoError(t, err)
+
+	fittest, _, err := solver.Epochs(5)
+	require.NoError(t, err)
+
+	ph := fittest.Phenotype()
+	assert.Len(t, ph.Gains, 4)
+	assert.Len(t, ph.Levels, 3)
+	assert.Equal(t, fitness(ph), fittest.Fitness())
+}
+
+func TestScaling(t *testing.T) {
+	solver, err := NewSolver(genotype.Binary[uint8](8, 16), sumOfGenes, 10,
+		WithSelection(selection.RouletteWheel()),
+		WithScaling(scaling.Rank(2)),
+	)
+	require.NoError(t, err)
+
+	ga := solver.(*gaSolver[[]uint8])
+	_, _, err = ga.calculateFitnessScores()
+	require.NoError(t, err)
+
+	stats := ga.population.Stats()
+	require.NoError(t, ga.selectBreedingPool())
+
+	for i := range ga.population.NIndividuals() {
+		phenotype := ga.schema.Init()
+		ga.population.Decode(i, &phenotype)
+		assert.Equal(t, sumOfGenes(phenotype), ga.population.Fitness(i), "raw fitness should be left untouched")
+	}
+	assert.Equal(t, stats.TotalFitness, ga.population.Stats().TotalFitness)
+}
+
+func TestScalingWithElitism(t *testing.T) {
+	offset := func(ph []uint8) float64 { return sumOfGenes(ph) - 500 }
+	for name, op := range map[string]scaling.Operator{
+		"linear":    scaling.Linear(2),
+		"boltzmann": scaling.Boltzmann(func(int) float64 { return 50 }),
+	} {
+		for _, minimize := range []bool{false, true} {
+			t.Run(fmt.Sprintf("should scale sorted %s fitness (minimize: %v)", name, minimize), func(t *testing.T) {
+				opts := []Option{
+					WithSelection(selection.RouletteWheel()),
+					WithElitism(2, 1),
+					WithScaling(op),
+				}
+				if minimize {
+					opts = append(opts, WithMinimize())
+				}
+				solver, err := NewSolver(genotype.Binary[uint8](8, 4), offset, 20, opts...)
+				require.NoError(t, err)
+
+				ga := solver.(*gaSolver[[]uint8])
+				_, _, err = ga.calculateFitnessScores()
+				require.NoError(t, err)
+				require.NoError(t, ga.selectBreedingPool())
+
+				// the population is now sorted, fittest first
+				n := ga.population.NIndividuals()
+				worst := ga.population.Fitness(n - 1)
+				var meanMerit, meanScaled float64
+				for i := range n {
+					merit := ga.population.Fitness(i) - worst
+					if minimize {
+						merit = -merit
+					}
+					meanMerit += merit / float64(n)
+					meanScaled += ga.scaled[i] / float64(n)
+
+					assert.False(t, math.IsNaN(ga.scaled[i]) || math.IsInf(ga.scaled[i], 0))
+					assert.GreaterOrEqual(t, ga.scaled[0], ga.scaled[i], "fittest should scale highest")
+					assert.LessOrEqual(t, ga.scaled[n-1], ga.scaled[i], "worst should scale lowest")
+				}
+				switch name {
+				case "linear":
+					assert.InDelta(t, meanMerit, meanScaled, 1e-9, "mean merit should be preserved")
+				case "boltzmann":
+					assert.Equal(t, 1.0, ga.scaled[0], "fittest should scale to 1")
+				}
+
+				_, _, err = solver.Epochs(50)
+				assert.NoError(t, err)
+			})
+		}
+	}
+}
+
+func TestMinimize(t *testing.T) {
+	var r recorder
+	solver, err := NewSolver(genotype.Binary[uint8](8, 4), sumOfGenes, 50,
+		WithSelection(selection.RouletteWheel()),
+		WithElitism(1, 2),
+		WithScaling(scaling.SigmaTruncation(2)),
+		WithMinimize(),
+		WithTargetFitness(100),
+		WithObserver[[]uint8](&r),
+	)
+	require.NoError(t, err)
+
+	fittest, found, err := solver.Epochs(500)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.LessOrEqual(t, fittest.Fitness(), 100.0)
+	assert.IsDecreasing(t, r.newBests)
+}
+
+func TestReplacement(t *testing.T) {
+	for _, n := range []int{1, 4} {
+		t.Run(fmt.Sprintf("should keep the fittest among parents and offspring with parallelism %d", n), func(t *testing.T) {
+			var bests []float64
+			var evaluations []int
+			solver, err := NewSolver(genotype.Binary[uint8](8, 16), sumOfGenes, 10,
+				WithSelection(selection.Truncation(0.5)),
+				WithReplacement(replacement.MuPlusLambda(30)),
+				WithParallelism(n),
+				WithObserver(ObserverFunc[[]uint8](func(e Event[[]uint8]) {
+					bests = append(bests, e.Stats.BestFitness)
+					evaluations = append(evaluations, e.Evaluations)
+				})),
+			)
+			require.NoError(t, err)
+			defer solver.Close()
+
+			_, _, err = solver.Epochs(20)
+			require.NoError(t, err)
+
+			assert.IsNonDecreasing(t, bests)
+			for i, e := range evaluations {
+				assert.Equal(t, 10+30*i, e)
+			}
+		})
+	}
+
+	t.Run("should discard the parents", func(t *testing.T) {
+		var evaluations int
+		solver, err := NewSolver(genotype.Binary[uint8](8, 16), sumOfGenes, 10,
+			WithSelection(selection.Tournament(2)),
+			WithReplacement(replacement.MuCommaLambda(20)),
+			WithObserver(ObserverFunc[[]uint8](func(e Event[[]uint8]) {
+				evaluations = e.Evaluations
+			})),
+		)
+		require.NoError(t, err)
+
+		_, _, err = solver.Epochs(5)
+		require.NoError(t, err)
+		assert.Equal(t, 10+20*4, evaluations)
+	})
+
+	t.Run("should fail with more elites than parents", func(t *testing.T) {
+		_, err := NewSolver(genotype.Binary[uint8](8, 16), sumOfGenes, 10,
+			WithReplacement(replacement.MuPlusLambda(2)),
+			WithElitism(2, 2),
+		)
+		assert.Error(t, err)
+
+		_, err = NewSolver(genotype.Binary[uint8](8, 16), sumOfGenes, 2,
+			WithReplacement(replacement.MuPlusLambda(10)),
+			WithElitism(3, 1),
+		)
+		assert.Error(t, err)
+	})
+
+	t.Run("should fail with too few offspring", func(t *testing.T) {
+		_, err := NewSolver(genotype.Binary[uint8](8, 16), sumOfGenes, 10,
+			WithSelection(selection.Tournament(2)),
+			WithReplacement(replacement.MuCommaLambda(5)),
+		)
+		assert.Error(t, err)
+	})
+}
+
+func TestSteadyState(t *testing.T) {
+	for _, tc := range []struct {
+		policy   SteadyStatePolicy
+		children int
+	}{
+		{ReplaceWorst, 1},
+		{ReplaceWorst, 2},
+		{ReplaceRandom, 2},
+		{ReplaceOldest, 3},
+	} {
+		t.Run(fmt.Sprintf("should evaluate only %d children with policy %d", tc.children, tc.policy), func(t *testing.T) {
+			var bests []float64
+			var evaluations int
+			solver, err := NewSolver(genotype.Binary[uint8](8, 16), sumOfGenes, 20,
+				WithSelection(selection.Tournament(2)),
+				WithSteadyState(tc.children, tc.policy),
+				WithObserver(ObserverFunc[[]uint8](func(e Event[[]uint8]) {
+					bests = append(bests, e.Stats.BestFitness)
+					evaluations = e.Evaluations
+				})),
+			)
+			require.NoError(t, err)
+
+			_, _, err = solver.Epochs(50)
+			require.NoError(t, err)
+			assert.Equal(t, 20+tc.children*49, evaluations)
+			if tc.policy == ReplaceWorst {
+				assert.IsNonDecreasing(t, bests)
+			}
+
+			// fitness values and aggregates should match the genotypes
+			ga := solver.(*gaSolver[[]uint8])
+			var total float64
+			for i := range ga.population.NIndividuals() {
+				ph := ga.schema.Init()
+				ga.population.Decode(i, &ph)
+				assert.Equal(t, sumOfGenes(ph), ga.population.Fitness(i))
+				total += ga.population.Fitness(i)
+			}
+			stats := ga.population.Stats()
+			assert.InDelta(t, total, stats.TotalFitness, 1e-9)
+			for i := range ga.population.NIndividuals() {
+				assert.LessOrEqual(t, ga.population.Fitness(i), stats.BestFitness)
+				assert.GreaterOrEqual(t, ga.population.Fitness(i), stats.WorstFitness)
+			}
+		})
+	}
+
+	t.Run("should replace the oldest individuals in turn", func(t *testing.T) {
+		solver, err := NewSolver(genotype.Binary[uint8](8, 16), sumOfGenes, 4,
+			WithSelection(selection.Random()),
+			WithSteadyState(1, ReplaceOldest),
+		)
+		require.NoError(t, err)
+
+		_, _, err = solver.Epochs(5)
+		require.NoError(t, err)
+		assert.Equal(t, []int{6, 3, 4, 5}, solver.(*gaSolver[[]uint8]).births)
+	})
+
+	t.Run("should not support elitism", func(t *testing.T) {
+		_, err := NewSolver(genotype.Binary[uint8](8, 16), sumOfGenes, 10,
+			WithSelection(selection.Random()),
+			WithSteadyState(1, ReplaceWorst),
+			WithElitism(1, 1),
+		)
+		assert.Error(t, err)
+	})
+}
+
+func TestFitnessCache(t *testing.T) {
+	schema, err := genotype.Build(func(bind genotype.BindFunc, ph *[]uint8) (s genotype.Spec) {
+		s.IntChromosome(bind(ph).Bits(8).Len(16)).
+			Crossover(crossover.Probability(0, crossover.TwoPoints()))
+		return
+	})
+	require.NoError(t, err)
+
+	for _, n := range []int{1, 4} {
+		t.Run(fmt.Sprintf("should not evaluate unchanged individuals again with parallelism %d", n), func(t *testing.T) {
+			var calls atomic.Int32
+			fitness := func(ph []uint8) float64 {
+				calls.Add(1)
+				return sumOfGenes(ph)
+			}
+
+			var last Event[[]uint8]
+			solver, err := NewSolver(schema, fitness, 20,
+				WithSelection(selection.Random()),
+				WithFitnessCache(100),
+				WithParallelism(n),
+				WithObserver(ObserverFunc[[]uint8](func(e Event[[]uint8]) {
+					last = e
+				})),
+			)
+			require.NoError(t, err)
+			defer solver.Close()
+
+			// offspring are copies of their parents, which were all evaluated
+			// in the first generation
+			_, _, err = solver.Epochs(5)
+			require.NoError(t, err)
+			assert.LessOrEqual(t, calls.Load(), int32(20))
+			assert.Equal(t, int(calls.Load()), last.Evaluations)
+			assert.Equal(t, 20*5, last.Evaluations+last.EvaluationsAvoided)
+			assert.GreaterOrEqual(t, last.EvaluationsAvoided, 20*4)
+		})
+	}
+}
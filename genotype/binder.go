@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"math"
+	"math/bits"
 	"reflect"
 	"slices"
 	"unsafe"
@@ -15,10 +17,11 @@ import (
 type BindFunc func(any) *GeneSpec
 
 type binder[T any] struct {
-	root  *T
-	root_ T
-	start uintptr
-	end   uintptr
+	root    *T
+	root_   T
+	start   uintptr
+	end     uintptr
+	initial []byte
 }
 
 func newBinder[T any]() (b binder[T]) {
@@ -31,6 +34,7 @@ func newBinder[T any]() (b binder[T]) {
 	b.root = &b.root_
 	b.start = uintptr(unsafe.Pointer(b.root))
 	b.end = b.start + unsafe.Sizeof(b.root_)
+	b.initial = bytes.Clone(unsafeBytes(b.root))
 	return
 }
 
@@ -63,7 +67,7 @@ func (b binder[T]) x(t reflect.Type, ptr uintptr) *GeneSpec {
 
 	case reflect.Slice:
 		g := b.x(t.Elem(), ptr)
-		g.isSlice = true
+		g.sliceType = t
 		g.cells *= g.len
 		g.len = 0
 		return g
@@ -107,12 +111,18 @@ func (b binder[T]) x(t reflect.Type, ptr uintptr) *GeneSpec {
 		}
 
 	default:
-		panic("invalid pointer type") // TODO
+		// only valid as a categorical gene, see OneOf
+		return &GeneSpec{
+			type_:    t,
+			cells:    1,
+			len:      1,
+			phOffset: ptr - b.start,
+		}
 	}
 }
 
 func (b binder[T]) validate() error {
-	if !memEq(b.root, &b.root_) {
+	if !bytes.Equal(unsafeBytes(b.root), b.initial) {
 		return errors.New("you should not change the root value") // TODO
 	}
 	return nil
@@ -131,6 +141,16 @@ func (s *Spec) IntChromosome(genes ...*GeneSpec) *ChromosomeSpec {
 	return s.addChromosome(reflect.Int, genes...).
 		Crossover(crossover.Probability(0.75, crossover.SinglePoint()))
 }
+
+// PermutationChromosome declares a chromosome of n integer genes, which always
+// hold a permutation of 0..n-1. Each gene takes just the bits needed to store
+// n-1, whatever its type.
+func (s *Spec) PermutationChromosome(n int, genes ...*GeneSpec) *ChromosomeSpec {
+	c := s.addChromosome(reflect.Int, genes...)
+	c.flags |= FlagPermutation
+	c.n = n
+	return c.Crossover(crossover.Probability(0.75, crossover.PMX()))
+}
 func (s *Spec) Float32Chromosome(genes ...*GeneSpec) *ChromosomeSpec {
 	return s.addChromosome(reflect.Float32, genes...)
 }
@@ -141,7 +161,9 @@ func (s *Spec) Float64Chromosome(genes ...*GeneSpec) *ChromosomeSpec {
 type ChromosomeSpec struct {
 	type_     reflect.Kind
 	flags     Flags
+	n         int
 	genes     []*GeneSpec
+	gray      bool
 	crossover crossover.Operator
 	mutate    mutation.Operator
 }
@@ -166,14 +188,27 @@ func (c *ChromosomeSpec) Mutate(op mutation.Operator) *ChromosomeSpec {
 	return c
 }
 
+// Gray stores all the integer genes of the chromosome in Gray code, as if Gray
+// were called on each of them.
+func (c *ChromosomeSpec) Gray() *ChromosomeSpec {
+	c.gray = true
+	return c
+}
+
 type GeneSpec struct {
-	type_    reflect.Type
-	isSlice  bool
-	cells    int
-	index    int
-	len      int
-	bits     int
-	phOffset uintptr
+	type_     reflect.Type
+	sliceType reflect.Type
+	cells     int
+	index     int
+	len       int
+	bits      int
+	phOffset  uintptr
+
+	bounded  bool
+	fixed    bool
+	min, max float64
+	gray     bool
+	choices  []reflect.Value
 }
 
 var emptyGeneSpec = GeneSpec{}
@@ -187,16 +222,260 @@ func (g *GeneSpec) Len(l int) *GeneSpec {
 	return g
 }
 func (g *GeneSpec) Bits(b int) *GeneSpec {
-	if b > g.type_.Bits() {
-		panic("specified bit width is too wide") // TODO
+	if err := checkWidth(g.type_, b); err != nil {
+		panic(err.Error()) // TODO
 	}
 
 	g.bits = b
 	return g
 }
 
+// Range bounds the values of the gene within [lo, hi], both included.
+// Integer genes then store their distance from lo in just the bits needed for
+// hi-lo, unless Bits is called afterwards: whenever randomization, crossover
+// or mutation leave bits past hi, they are redrawn uniformly within the range,
+// so that all values are equally likely to come up. Floating point genes are
+// clamped.
+func (g *GeneSpec) Range(lo, hi float64) *GeneSpec {
+	if err := checkRange(g.type_, lo, hi); err != nil {
+		panic(err.Error()) // TODO
+	}
+
+	g.bounded, g.fixed = true, false
+	g.min, g.max = lo, hi
+	g.choices = nil
+	if g.isInteger() {
+		g.bits = max(bits.Len64(g.bounds().span), 1)
+	}
+	return g
+}
+
+// Between makes a floating point gene fixed point: it stores an unsigned
+// integer of as many bits as set by Bits, mapped linearly onto [lo, hi], so
+// that it can be declared on an integer chromosome and handled by binary
+// operators.
+func (g *GeneSpec) Between(lo, hi float64) *GeneSpec {
+	if err := checkFixedRange(g.type_, lo, hi); err != nil {
+		panic(err.Error()) // TODO
+	}
+
+	g.bounded, g.fixed = true, true
+	g.min, g.max = lo, hi
+	g.choices = nil
+	return g
+}
+
+// OneOf makes a categorical gene, whose value is one of values: it stores the
+// index of the value in just the bits needed, redrawn like those of Range when
+// past the last one. Values must be assignable to the type of the gene, or share its kind,
+// and can be of any type, while phenotype values not among them encode as the
+// first one.
+func (g *GeneSpec) OneOf(values ...any) *GeneSpec {
+	if len(values) == 0 {
+		panic("no values to choose from") // TODO
+	}
+
+	choices := make([]reflect.Value, len(values))
+	for i, v := range values {
+		rv := reflect.ValueOf(v)
+		switch {
+		case !rv.IsValid():
+			panic(fmt.Sprintf("invalid value for %v: %v", g.type_, v)) // TODO
+		case rv.Type().AssignableTo(g.type_):
+		case rv.Kind() == g.type_.Kind() && rv.Type().ConvertibleTo(g.type_):
+			rv = rv.Convert(g.type_)
+		default:
+			panic(fmt.Sprintf("invalid value for %v: %v", g.type_, v)) // TODO
+		}
+
+		choices[i] = reflect.New(g.type_).Elem()
+		choices[i].Set(rv)
+	}
+
+	g.choices = choices
+	g.bounded, g.fixed = true, false
+	g.min, g.max = 0, float64(len(choices)-1)
+	g.bits = max(bits.Len(uint(len(choices)-1)), 1)
+	return g
+}
+
+// Gray stores the gene in binary reflected Gray code, where consecutive values
+// differ by a single bit, so that flipping a bit is more likely to make a small
+// change. It applies to integer and fixed point genes; operators keep working
+// on the raw bits.
+func (g *GeneSpec) Gray() *GeneSpec {
+	g.gray = true
+	return g
+}
+
+// checkWidth fails if genes of type t cannot be b bits wide. Categorical genes
+// of any type can take up to 64 bits.
+func checkWidth(t reflect.Type, b int) error {
+	limit := 64
+	switch k := t.Kind(); {
+	case k == reflect.Bool:
+		limit = 1
+	case isNumeric(k):
+		limit = t.Bits()
+	}
+	if b < 0 {
+		return fmt.Errorf("invalid bit width: %d", b)
+	}
+	if b > limit {
+		return fmt.Errorf("specified bit width is too wide for %v: %d", t, b)
+	}
+	return nil
+}
+
+// checkRange fails if genes of type t cannot be bounded within [lo, hi].
+func checkRange(t reflect.Type, lo, hi float64) error {
+	if !(lo <= hi) {
+		return fmt.Errorf("invalid range: [%v, %v]", lo, hi)
+	}
+
+	switch t.Kind() {
+	case reflect.Float32, reflect.Float64:
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		limit := math.Ldexp(1, t.Bits()-1)
+		if math.Trunc(lo) != lo || math.Trunc(hi) != hi || lo < -limit || hi >= limit {
+			return fmt.Errorf("invalid range for %v: [%v, %v]", t, lo, hi)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		limit := math.Ldexp(1, t.Bits())
+		if math.Trunc(lo) != lo || math.Trunc(hi) != hi || lo < 0 || hi >= limit {
+			return fmt.Errorf("invalid range for %v: [%v, %v]", t, lo, hi)
+		}
+	default:
+		return fmt.Errorf("cannot bound genes of type %v", t)
+	}
+	return nil
+}
+
+// checkFixedRange fails if genes of type t cannot be fixed point within
+// [lo, hi].
+func checkFixedRange(t reflect.Type, lo, hi float64) error {
+	if !(lo <= hi) || math.IsInf(lo, 0) || math.IsInf(hi, 0) {
+		return fmt.Errorf("invalid range: [%v, %v]", lo, hi)
+	}
+	if k := t.Kind(); k != reflect.Float32 && k != reflect.Float64 {
+		return fmt.Errorf("cannot make fixed point genes of type %v", t)
+	}
+	return nil
+}
+
+func isNumeric(k reflect.Kind) bool {
+	switch k {
+	case
+		reflect.Bool,
+		reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+func (g *GeneSpec) isInteger() bool {
+	switch g.type_.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return false
+	}
+	return true
+}
+
+// bounds returns the bounds of the genes declared by the spec.
+func (g *GeneSpec) bounds() (b bounds) {
+	if !g.bounded {
+		return
+	}
+
+	b.bounded = true
+	b.min, b.max = g.min, g.max
+	if g.choices != nil {
+		b.span = uint64(len(g.choices) - 1)
+		return
+	}
+	if g.fixed {
+		b.fixed = true
+		b.steps = math.Ldexp(1, g.bits) - 1
+		return
+	}
+
+	b.typeBits = g.type_.Bits()
+	switch g.type_.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		b.signed = true
+		b.lo = uint64(int64(g.min))
+		b.span = uint64(int64(g.max)) - b.lo
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		b.lo = uint64(g.min)
+		b.span = uint64(g.max) - b.lo
+	}
+	return
+}
+
+// maxFixedBits is the widest fixed point gene whose steps are all exactly
+// represented by a float64.
+const maxFixedBits = 53
+
+// checkBits fails if the gene is too narrow for its range, or a fixed point
+// gene too wide.
+func (g *GeneSpec) checkBits() error {
+	if g.fixed && g.bits > maxFixedBits {
+		return fmt.Errorf("fixed point genes cannot be wider than %d bits, not %d: use Bits", maxFixedBits, g.bits)
+	}
+	if !g.bounded || !g.isInteger() {
+		return nil
+	}
+	if need := bits.Len64(g.bounds().span); g.bits < need {
+		return fmt.Errorf("range [%v, %v] needs %d bits, not %d", g.min, g.max, need, g.bits)
+	}
+	return nil
+}
+
 type OpSpec struct{}
 
+// sizePermutation checks that the chromosome declares n integer genes, and
+// sets their width to the bits needed to store n-1.
+func (c *ChromosomeSpec) sizePermutation() error {
+	if c.n <= 0 {
+		return fmt.Errorf("invalid permutation size: %d", c.n)
+	}
+
+	width := max(bits.Len(uint(c.n-1)), 1)
+	count := 0
+	for _, g := range c.genes {
+		if g.len*g.cells == 0 {
+			continue
+		}
+		if g.bounded {
+			return errors.New("permutation genes cannot have a range")
+		}
+		if g.gray || c.gray {
+			return errors.New("permutation genes cannot be Gray coded")
+		}
+
+		limit := g.type_.Bits()
+		switch g.type_.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			limit-- // the sign bit is not available
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		default:
+			return fmt.Errorf("permutation genes must be integers, not %v", g.type_)
+		}
+		if width > limit {
+			return fmt.Errorf("type %v is too narrow for a permutation of %d genes", g.type_, c.n)
+		}
+
+		g.bits = width
+		count += g.len * g.cells
+	}
+	if count != c.n {
+		return fmt.Errorf("permutation of %d genes declares %d genes", c.n, count)
+	}
+	return nil
+}
+
 func Build[Phenotype any](spec func(bind BindFunc, ph *Phenotype) (s Spec)) (schema Schema[Phenotype], err error) {
 	b := newBinder[Phenotype]()
 	s := spec(b.bind, b.root)
@@ -205,14 +484,22 @@ func Build[Phenotype any](spec func(bind BindFunc, ph *Phenotype) (s Spec)) (sch
 		return
 	}
 
-	for _, cs := range s {
+	for i, cs := range s {
 		c := Chromosome{
 			type_:      cs.type_,
+			flags:      cs.flags,
 			bytesIndex: schema.sizeInBytes,
 			crossover:  cs.crossover,
 			mutate:     cs.mutate,
 		}
 
+		if cs.flags&FlagPermutation != 0 {
+			if err = cs.sizePermutation(); err != nil {
+				err = fmt.Errorf("chromosome %d: %w", i, err)
+				return
+			}
+		}
+
 		slices.SortStableFunc(cs.genes, func(a, b *GeneSpec) int {
 			return b.bits - a.bits
 		})
@@ -220,10 +507,36 @@ func Build[Phenotype any](spec func(bind BindFunc, ph *Phenotype) (s Spec)) (sch
 		var bf bestFitDecreasingAllocator
 
 		for _, g := range cs.genes {
-			bytes := g.type_.Align()
+			if g.len*g.cells == 0 {
+				continue
+			}
+			if err = g.checkBits(); err != nil {
+				err = fmt.Errorf("chromosome %d: %w", i, err)
+				return
+			}
+			if g.fixed && cs.type_ != reflect.Int {
+				err = fmt.Errorf("chromosome %d: fixed point genes belong to integer chromosomes", i)
+				return
+			}
+			if g.choices == nil && !isNumeric(g.type_.Kind()) {
+				err = fmt.Errorf("chromosome %d: genes of type %v need OneOf", i, g.type_)
+				return
+			}
+			if g.choices != nil && cs.type_ != reflect.Int {
+				err = fmt.Errorf("chromosome %d: categorical genes belong to integer chromosomes", i)
+				return
+			}
+			gray := g.gray || cs.gray
+			if gray && !g.isInteger() && !g.fixed {
+				err = fmt.Errorf("chromosome %d: cannot Gray code genes of type %v", i, g.type_)
+				return
+			}
+
+			bytes := int(g.type_.Size())
+			bounds := g.bounds()
 
 			var dynamic func(ptr, i uintptr) uintptr
-			if g.isSlice {
+			if g.sliceType != nil {
 				dynamic = func(ptr, i uintptr) uintptr {
 					slice := *(*[]any)(unsafe.Pointer(ptr))
 					dataptr := unsafe.Pointer(unsafe.SliceData(slice))
@@ -243,8 +556,12 @@ func Build[Phenotype any](spec func(bind BindFunc, ph *Phenotype) (s Spec)) (sch
 							type_:           g.type_.Kind(), // TODO check compatibility and emit warnings
 							phenotypeOffset: g.phOffset,
 							dynamic:         dynamic,
+							dynamicType:     g.sliceType,
 							dynamicIndex:    index + uintptr(j*bytes),
 							locus:           locus,
+							bounds:          bounds,
+							gray:            gray,
+							choices:         g.choices,
 						}
 						c.genes = append(c.genes, gene)
 					}
@@ -263,6 +580,9 @@ func Build[Phenotype any](spec func(bind BindFunc, ph *Phenotype) (s Spec)) (sch
 					type_:           g.type_.Kind(), // TODO check compatibility and emit warnings
 					phenotypeOffset: g.phOffset + uintptr(i*bytes),
 					locus:           locus,
+					bounds:          bounds,
+					gray:            gray,
+					choices:         g.choices,
 				}
 
 				c.genes = append(c.genes, gene)
@@ -271,17 +591,20 @@ func Build[Phenotype any](spec func(bind BindFunc, ph *Phenotype) (s Spec)) (sch
 
 		c.bytesLength = bf.nBytes()
 
+		c.layout = c.buildLayout()
+		if c.crossover != nil {
+			c.crossover = crossover.Bind(c.crossover, c.layout)
+		}
+		if c.mutate != nil {
+			c.mutate = mutation.Bind(c.mutate, c.layout)
+		}
+
 		schema.chromosomes = append(schema.chromosomes, c)
 		schema.sizeInBytes += c.bytesLength
 	}
 	return
 }
 
-func memEq[T any](a, b *T) bool {
-	aBytes := unsafeBytes(a)
-	bBytes := unsafeBytes(b)
-	return bytes.Equal(aBytes, bBytes)
-}
 func unsafeBytes[T any](x *T) []byte {
 	ptr := (*byte)(unsafe.Pointer(x))
 	return unsafe.Slice(ptr, unsafe.Sizeof(*x))
@@ -2,10 +2,16 @@ package genotype_test
 
 import (
 	"math"
+	"math/bits"
+	"math/rand/v2"
 	"testing"
 
+	"github.com/mbolis/genetta/crossover"
 	"github.com/mbolis/genetta/genotype"
+	"github.com/mbolis/genetta/internal/testutil/stats"
+	"github.com/mbolis/genetta/mutation"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestBuildOptions(t *testing.T) {
@@ -281,3 +287,619 @@ func TestBuildSlice(t *testing.T) {
 		assert.Equal(t, []int{0, 2, 3, 4, 0}, d)
 	})
 }
+
+func TestBuildGeneLayout(t *testing.T) {
+	for name, op := range map[string]crossover.Operator{
+		"k-points": crossover.GeneKPoints(2),
+		"uniform":  crossover.GeneUniform(0.5),
+	} {
+		t.Run("should bind the gene layout to "+name+" crossover", func(t *testing.T) {
+			s, err := genotype.Build(func(bind genotype.BindFunc, ph *[5]uint16) (s genotype.Spec) {
+				s.IntChromosome(bind(ph).Bits(12)).
+					Crossover(op)
+				return
+			})
+			assert.NoError(t, err)
+
+			mom, dad := s.Make(1), s.Make(1)
+			s.Encode(&[5]uint16{}, mom)
+			s.Encode(&[5]uint16{0xfff, 0xfff, 0xfff, 0xfff, 0xfff}, dad)
+
+			for range 1000 {
+				child1, child2 := s.Make(1), s.Make(1)
+				assert.NoError(t, s.Crossover(mom, dad, child1, child2))
+
+				var ph1, ph2 [5]uint16
+				s.Decode(&ph1, child1)
+				s.Decode(&ph2, child2)
+				for i := range ph1 {
+					assert.Contains(t, []uint16{0, 0xfff}, ph1[i])
+					assert.Equal(t, 0xfff^ph1[i], ph2[i])
+				}
+			}
+		})
+	}
+}
+
+type realStruct struct {
+	a float32
+	b [3]float64
+	c float32
+}
+
+func TestBuildRealCrossover(t *testing.T) {
+	s, err := genotype.Build(func(bind genotype.BindFunc, ph *realStruct) (s genotype.Spec) {
+		s.Float32Chromosome(bind(&ph.a), bind(&ph.c)).
+			Crossover(crossover.WholeArithmetic(0.25))
+		s.Float64Chromosome(bind(&ph.b)).
+			Crossover(crossover.WholeArithmetic(0.5))
+		return
+	})
+	assert.NoError(t, err)
+
+	mom, dad := s.Make(1), s.Make(1)
+	s.Encode(&realStruct{0, [3]float64{0, 2, 4}, 4}, mom)
+	s.Encode(&realStruct{8, [3]float64{2, 4, 6}, 0}, dad)
+
+	child1, child2 := s.Make(1), s.Make(1)
+	assert.NoError(t, s.Crossover(mom, dad, child1, child2))
+
+	var ph1, ph2 realStruct
+	s.Decode(&ph1, child1)
+	s.Decode(&ph2, child2)
+	assert.Equal(t, realStruct{6, [3]float64{1, 3, 5}, 1}, ph1)
+	assert.Equal(t, realStruct{2, [3]float64{1, 3, 5}, 3}, ph2)
+}
+
+func TestBuildPermutation(t *testing.T) {
+	s, err := genotype.Build(func(bind genotype.BindFunc, ph *[10]int) (s genotype.Spec) {
+		s.PermutationChromosome(10, bind(ph)).
+			Mutate(mutation.Swap())
+		return
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, s.Size(), "each gene should take 4 bits")
+
+	decode := func(data []byte) []int {
+		var ph [10]int
+		s.Decode(&ph, data)
+		return ph[:]
+	}
+
+	mom, dad := s.Make(1), s.Make(1)
+	s.Randomize(mom)
+	s.Randomize(dad)
+	for range 1000 {
+		child1, child2 := s.Make(1), s.Make(1)
+		assert.NoError(t, s.Crossover(mom, dad, child1, child2))
+		assert.NoError(t, s.Mutate(child1, child2))
+
+		assert.ElementsMatch(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, decode(child1))
+		assert.ElementsMatch(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, decode(child2))
+		mom, dad = child1, child2
+	}
+
+	t.Run("should reject mismatched sizes", func(t *testing.T) {
+		_, err := genotype.Build(func(bind genotype.BindFunc, ph *[10]int) (s genotype.Spec) {
+			s.PermutationChromosome(8, bind(ph))
+			return
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("should reject non integer genes", func(t *testing.T) {
+		_, err := genotype.Build(func(bind genotype.BindFunc, ph *[10]float64) (s genotype.Spec) {
+			s.PermutationChromosome(10, bind(ph))
+			return
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestBuildRealMutation(t *testing.T) {
+	s, err := genotype.Build(func(bind genotype.BindFunc, ph *realStruct) (s genotype.Spec) {
+		s.Float32Chromosome(bind(&ph.a), bind(&ph.c))
+		s.Float64Chromosome(bind(&ph.b)).
+			Mutate(mutation.Gaussian(1, 1))
+		return
+	})
+	assert.NoError(t, err)
+
+	data := s.Make(1)
+	s.Encode(&realStruct{1, [3]float64{2, 3, 4}, 5}, data)
+	assert.NoError(t, s.Mutate(data))
+
+	var ph realStruct
+	s.Decode(&ph, data)
+	assert.Equal(t, float32(1), ph.a)
+	assert.Equal(t, float32(5), ph.c)
+	for i, v := range ph.b {
+		assert.False(t, math.IsNaN(v))
+		assert.NotEqual(t, float64(i+2), v)
+	}
+}
+
+type boundedStruct struct {
+	i  int8
+	u  uint16
+	f  float64
+	fs [2]float32
+}
+
+func buildBounded(t *testing.T) genotype.Schema[boundedStruct] {
+	s, err := genotype.Build(func(bind genotype.BindFunc, ph *boundedStruct) (s genotype.Spec) {
+		s.IntChromosome(bind(&ph.i).Range(-10, 20), bind(&ph.u).Range(100, 103)).
+			Mutate(mutation.BitString(2))
+		s.Float64Chromosome(bind(&ph.f).Range(-1, 1), bind(&ph.fs).Range(0, 5)).
+			Crossover(crossover.BLXAlpha(0.5)).
+			Mutate(mutation.UniformReset(0.5))
+		return
+	})
+	require.NoError(t, err)
+	return s
+}
+
+func assertBounded(t *testing.T, ph boundedStruct) {
+	t.Helper()
+	assert.True(t, ph.i >= -10 && ph.i <= 20, "%d out of range", ph.i)
+	assert.True(t, ph.u >= 100 && ph.u <= 103, "%d out of range", ph.u)
+	assert.True(t, ph.f >= -1 && ph.f <= 1, "%f out of range", ph.f)
+	for _, f := range ph.fs {
+		assert.True(t, f >= 0 && f <= 5, "%f out of range", f)
+	}
+}
+
+func TestBuildRange(t *testing.T) {
+	s := buildBounded(t)
+	assert.Equal(t, 1+16, s.Size(), "integer genes should take just the bits needed for their range")
+
+	t.Run("should clamp out of range values", func(t *testing.T) {
+		data := s.Make(1)
+		s.Encode(&boundedStruct{-50, 5000, 3, [2]float32{-1, 2}}, data)
+
+		var ph boundedStruct
+		s.Decode(&ph, data)
+		assert.Equal(t, boundedStruct{-10, 103, 1, [2]float32{0, 2}}, ph)
+	})
+
+	t.Run("should randomize within range", func(t *testing.T) {
+		seen := map[int8]bool{}
+		for range 1000 {
+			data := s.Make(1)
+			s.Randomize(data)
+
+			var ph boundedStruct
+			s.Decode(&ph, data)
+			assertBounded(t, ph)
+			seen[ph.i] = true
+		}
+		assert.Len(t, seen, 31)
+	})
+
+	t.Run("should decode any bit pattern within range", func(t *testing.T) {
+		for range 1000 {
+			data := s.Make(1)
+			for i := range data {
+				data[i] = byte(rand.Uint())
+			}
+
+			var ph boundedStruct
+			s.Decode(&ph, data)
+			assertBounded(t, ph)
+		}
+	})
+
+	t.Run("should breed within range", func(t *testing.T) {
+		mom, dad := s.Make(1), s.Make(1)
+		s.Randomize(mom)
+		s.Randomize(dad)
+		for range 1000 {
+			child1, child2 := s.Make(1), s.Make(1)
+			require.NoError(t, s.Crossover(mom, dad, child1, child2))
+			require.NoError(t, s.Mutate(child1, child2))
+
+			var ph boundedStruct
+			s.Decode(&ph, child1)
+			assertBounded(t, ph)
+			s.Decode(&ph, child2)
+			assertBounded(t, ph)
+			mom, dad = child1, child2
+		}
+	})
+
+	t.Run("should draw values uniformly within range", func(t *testing.T) {
+		// 8 bit patterns for 5 values, with and without Gray code
+		for _, gray := range []bool{false, true} {
+			s, err := genotype.Build(func(bind genotype.BindFunc, ph *[2]uint8) (s genotype.Spec) {
+				g := bind(&ph[0]).Range(0, 4)
+				if gray {
+					g.Gray()
+				}
+				s.IntChromosome(g, bind(&ph[1]).OneOf(uint8(0), uint8(1), uint8(2), uint8(3), uint8(4))).
+					Mutate(mutation.BitString(1))
+				return
+			})
+			require.NoError(t, err)
+
+			const n = 10_000
+			var randomized, mutated [2][5]float64
+			for range n {
+				var ph [2]uint8
+				data := s.Make(1)
+				s.Randomize(data)
+				s.Decode(&ph, data)
+				for i, v := range ph {
+					randomized[i][v]++
+				}
+
+				// random bits stay random when flipped, unlike the values
+				// they would wrap around to
+				for i := range data {
+					data[i] = byte(rand.Uint())
+				}
+				require.NoError(t, s.Mutate(data))
+				s.Decode(&ph, data)
+				for i, v := range ph {
+					mutated[i][v]++
+				}
+			}
+
+			// wrapping around would make 0, 1 and 2 twice as likely as 3 and 4
+			for i := range 2 {
+				_, p := stats.UniformChiSquareP(randomized[i][:], n/5)
+				assert.Greater(t, p, 1e-4, "randomized gene %d should be uniform (gray: %v)", i, gray)
+				_, p = stats.UniformChiSquareP(mutated[i][:], n/5)
+				assert.Greater(t, p, 1e-4, "mutated gene %d should be uniform (gray: %v)", i, gray)
+			}
+		}
+	})
+
+	t.Run("should reject invalid ranges", func(t *testing.T) {
+		_, err := genotype.Build(func(bind genotype.BindFunc, ph *boundedStruct) (s genotype.Spec) {
+			s.IntChromosome(bind(&ph.u).Range(0, 1000).Bits(4))
+			return
+		})
+		assert.Error(t, err)
+
+		assert.Panics(t, func() {
+			genotype.Build(func(bind genotype.BindFunc, ph *boundedStruct) (s genotype.Spec) {
+				s.IntChromosome(bind(&ph.i).Range(0, 200))
+				return
+			})
+		})
+		assert.Panics(t, func() {
+			genotype.Build(func(bind genotype.BindFunc, ph *boundedStruct) (s genotype.Spec) {
+				s.IntChromosome(bind(&ph.i).Range(0.5, 1))
+				return
+			})
+		})
+		assert.Panics(t, func() {
+			genotype.Build(func(bind genotype.BindFunc, ph *boundedStruct) (s genotype.Spec) {
+				s.Float64Chromosome(bind(&ph.f).Range(1, -1))
+				return
+			})
+		})
+	})
+}
+
+type fixedStruct struct {
+	alpha float64
+	beta  [2]float32
+}
+
+func TestBuildFixedPoint(t *testing.T) {
+	s, err := genotype.Build(func(bind genotype.BindFunc, ph *fixedStruct) (s genotype.Spec) {
+		s.IntChromosome(bind(&ph.alpha).Bits(10).Between(0, 1), bind(&ph.beta).Bits(4).Between(-1, 1)).
+			Crossover(crossover.KPoints(2)).
+			Mutate(mutation.BitString(2))
+		return
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, s.Size())
+
+	t.Run("should quantize values", func(t *testing.T) {
+		data := s.Make(1)
+		for _, tc := range []struct{ in, out fixedStruct }{
+			{fixedStruct{0, [2]float32{-1, 1}}, fixedStruct{0, [2]float32{-1, 1}}},
+			{fixedStruct{1, [2]float32{0.2, -0.2}}, fixedStruct{1, [2]float32{float32(-1 + 2*9.0/15), float32(-1 + 2*6.0/15)}}},
+			{fixedStruct{0.3, [2]float32{}}, fixedStruct{307.0 / 1023, [2]float32{float32(-1 + 2*8.0/15), float32(-1 + 2*8.0/15)}}},
+			{fixedStruct{2, [2]float32{-3, 3}}, fixedStruct{1, [2]float32{-1, 1}}},
+		} {
+			s.Encode(&tc.in, data)
+
+			var ph fixedStruct
+			s.Decode(&ph, data)
+			assert.InDelta(t, tc.out.alpha, ph.alpha, 1e-12)
+			assert.InDeltaSlice(t, tc.out.beta[:], ph.beta[:], 1e-6)
+		}
+	})
+
+	t.Run("should breed quantized values", func(t *testing.T) {
+		mom, dad := s.Make(1), s.Make(1)
+		s.Randomize(mom)
+		s.Randomize(dad)
+		for range 1000 {
+			child1, child2 := s.Make(1), s.Make(1)
+			require.NoError(t, s.Crossover(mom, dad, child1, child2))
+			require.NoError(t, s.Mutate(child1, child2))
+
+			var ph fixedStruct
+			s.Decode(&ph, child1)
+			assert.True(t, ph.alpha >= 0 && ph.alpha <= 1)
+			steps := ph.alpha * 1023
+			assert.InDelta(t, math.Round(steps), steps, 1e-9)
+			for _, b := range ph.beta {
+				assert.True(t, b >= -1 && b <= 1)
+			}
+			mom, dad = child1, child2
+		}
+	})
+
+	t.Run("should reject invalid fixed point genes", func(t *testing.T) {
+		_, err := genotype.Build(func(bind genotype.BindFunc, ph *fixedStruct) (s genotype.Spec) {
+			s.IntChromosome(bind(&ph.alpha).Between(0, 1))
+			return
+		})
+		assert.Error(t, err, "should need an explicit width")
+
+		_, err = genotype.Build(func(bind genotype.BindFunc, ph *fixedStruct) (s genotype.Spec) {
+			s.Float64Chromosome(bind(&ph.alpha).Bits(10).Between(0, 1))
+			return
+		})
+		assert.Error(t, err, "should belong to an integer chromosome")
+
+		assert.Panics(t, func() {
+			genotype.Build(func(bind genotype.BindFunc, ph *boundedStruct) (s genotype.Spec) {
+				s.IntChromosome(bind(&ph.i).Bits(4).Between(0, 1))
+				return
+			})
+		})
+	})
+}
+
+type grayStruct struct {
+	a uint8
+	b int16
+	f float64
+}
+
+// hamming counts the bits that differ between a and b
+func hamming(a, b []byte) (d int) {
+	for i := range a {
+		d += bits.OnesCount8(a[i] ^ b[i])
+	}
+	return
+}
+
+func TestBuildGray(t *testing.T) {
+	s, err := genotype.Build(func(bind genotype.BindFunc, ph *grayStruct) (s genotype.Spec) {
+		s.IntChromosome(
+			bind(&ph.a).Bits(4).Gray(),
+			bind(&ph.b).Range(-8, 7).Gray(),
+			bind(&ph.f).Bits(6).Between(0, 63).Gray(),
+		)
+		return
+	})
+	require.NoError(t, err)
+
+	t.Run("should change a single bit between consecutive values", func(t *testing.T) {
+		prev, data := s.Make(1), s.Make(1)
+		s.Encode(&grayStruct{0, -8, 0}, prev)
+		for v := 1; v < 16; v++ {
+			in := grayStruct{uint8(v), int16(v - 8), float64(v)}
+			s.Encode(&in, data)
+			assert.Equal(t, 3, hamming(prev, data))
+
+			var out grayStruct
+			s.Decode(&out, data)
+			assert.Equal(t, in, out)
+			copy(prev, data)
+		}
+	})
+
+	t.Run("should randomize uniformly", func(t *testing.T) {
+		seen := map[int16]bool{}
+		for range 1000 {
+			data := s.Make(1)
+			s.Randomize(data)
+
+			var ph grayStruct
+			s.Decode(&ph, data)
+			assert.True(t, ph.b >= -8 && ph.b <= 7)
+			seen[ph.b] = true
+		}
+		assert.Len(t, seen, 16)
+	})
+
+	t.Run("should Gray code whole chromosomes", func(t *testing.T) {
+		s, err := genotype.Build(func(bind genotype.BindFunc, ph *[4]uint8) (s genotype.Spec) {
+			s.IntChromosome(bind(ph).Bits(3)).Gray()
+			return
+		})
+		require.NoError(t, err)
+
+		prev, data := s.Make(1), s.Make(1)
+		for v := uint8(1); v < 8; v++ {
+			s.Encode(&[4]uint8{v, v, v, v}, data)
+			assert.Equal(t, 4, hamming(prev, data))
+			copy(prev, data)
+		}
+	})
+
+	t.Run("should reject non integer genes", func(t *testing.T) {
+		_, err := genotype.Build(func(bind genotype.BindFunc, ph *grayStruct) (s genotype.Spec) {
+			s.Float64Chromosome(bind(&ph.f)).Gray()
+			return
+		})
+		assert.Error(t, err)
+
+		_, err = genotype.Build(func(bind genotype.BindFunc, ph *[4]int) (s genotype.Spec) {
+			s.PermutationChromosome(4, bind(ph)).Gray()
+			return
+		})
+		assert.Error(t, err)
+	})
+}
+
+type Activation string
+
+type point struct{ x, y int }
+
+type categoricalStruct struct {
+	activation string
+	layers     [3]Activation
+	shape      point
+	scale      int8
+}
+
+func TestBuildOneOf(t *testing.T) {
+	activations := []any{"relu", "tanh", "sigmoid"}
+
+	s, err := genotype.Build(func(bind genotype.BindFunc, ph *categoricalStruct) (s genotype.Spec) {
+		s.IntChromosome(
+			bind(&ph.activation).OneOf(activations...),
+			bind(&ph.layers).OneOf(activations...),
+			bind(&ph.shape).OneOf(point{0, 0}, point{1, 2}, point{3, 4}, point{5, 6}, point{7, 8}),
+			bind(&ph.scale).OneOf(int8(-1), int8(1)),
+		).
+			Crossover(crossover.KPoints(2)).
+			Mutate(mutation.RandomReset(0.5))
+		return
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, s.Size(), "genes should take just the bits needed")
+
+	assertValid := func(t *testing.T, ph categoricalStruct) {
+		t.Helper()
+		assert.Contains(t, activations, ph.activation)
+		for _, a := range ph.layers {
+			assert.Contains(t, activations, string(a))
+		}
+		assert.True(t, ph.shape.x%2 == 1 && ph.shape.y == ph.shape.x+1 || ph.shape == point{}, "invalid shape %v", ph.shape)
+		assert.Contains(t, []int8{-1, 1}, ph.scale)
+	}
+
+	t.Run("should encode choices", func(t *testing.T) {
+		data := s.Make(1)
+		for _, ph := range []categoricalStruct{
+			{"relu", [3]Activation{"tanh", "sigmoid", "relu"}, point{3, 4}, 1},
+			{"sigmoid", [3]Activation{"relu", "relu", "tanh"}, point{7, 8}, -1},
+		} {
+			s.Encode(&ph, data)
+
+			var decoded categoricalStruct
+			s.Decode(&decoded, data)
+			assert.Equal(t, ph, decoded)
+		}
+
+		s.Encode(&categoricalStruct{"elu", [3]Activation{}, point{1, 1}, 0}, data)
+		var decoded categoricalStruct
+		s.Decode(&decoded, data)
+		assert.Equal(t, categoricalStruct{"relu", [3]Activation{"relu", "relu", "relu"}, point{0, 0}, -1}, decoded,
+			"values out of the set should encode as the first")
+	})
+
+	t.Run("should decode any bit pattern among choices", func(t *testing.T) {
+		for range 1000 {
+			data := s.Make(1)
+			for i := range data {
+				data[i] = byte(rand.Uint())
+			}
+
+			var ph categoricalStruct
+			s.Decode(&ph, data)
+			assertValid(t, ph)
+		}
+	})
+
+	t.Run("should pick choices uniformly", func(t *testing.T) {
+		counts := map[string]int{}
+		mom, dad := s.Make(1), s.Make(1)
+		s.Randomize(mom)
+		s.Randomize(dad)
+		for range 3000 {
+			child1, child2 := s.Make(1), s.Make(1)
+			require.NoError(t, s.Crossover(mom, dad, child1, child2))
+			require.NoError(t, s.Mutate(child1, child2))
+
+			var ph categoricalStruct
+			s.Decode(&ph, child1)
+			assertValid(t, ph)
+			counts[ph.activation]++
+			mom, dad = child1, child2
+		}
+		for _, a := range activations {
+			assert.InDelta(t, 1000, counts[a.(string)], 150)
+		}
+	})
+
+	t.Run("should reject invalid categorical genes", func(t *testing.T) {
+		_, err := genotype.Build(func(bind genotype.BindFunc, ph *categoricalStruct) (s genotype.Spec) {
+			s.IntChromosome(bind(&ph.activation))
+			return
+		})
+		assert.Error(t, err, "should need choices")
+
+		assert.Panics(t, func() {
+			genotype.Build(func(bind genotype.BindFunc, ph *categoricalStruct) (s genotype.Spec) {
+				s.IntChromosome(bind(&ph.scale).OneOf(1, 2))
+				return
+			})
+		})
+		assert.Panics(t, func() {
+			genotype.Build(func(bind genotype.BindFunc, ph *categoricalStruct) (s genotype.Spec) {
+				s.IntChromosome(bind(&ph.activation).OneOf())
+				return
+			})
+		})
+	})
+}
+
+type mixedStruct struct {
+	X float64
+	N int32
+}
+
+func TestBuildMixedRealChromosome(t *testing.T) {
+	s, err := genotype.Build(func(bind genotype.BindFunc, ph *mixedStruct) (s genotype.Spec) {
+		s.Float64Chromosome(bind(&ph.X), bind(&ph.N)).
+			Crossover(crossover.SBX(2))
+		return
+	})
+	require.NoError(t, err)
+
+	mom, dad := s.Make(1), s.Make(1)
+	s.Encode(&mixedStruct{1, 7}, mom)
+	s.Encode(&mixedStruct{3, 42}, dad)
+	for range 100 {
+		child1, child2 := s.Make(1), s.Make(1)
+		require.NoError(t, s.Crossover(mom, dad, child1, child2))
+
+		var ph1, ph2 mixedStruct
+		s.Decode(&ph1, child1)
+		s.Decode(&ph2, child2)
+		assert.Equal(t, int32(7), ph1.N)
+		assert.Equal(t, int32(42), ph2.N)
+		assert.InDelta(t, 4, ph1.X+ph2.X, 1e-9, "SBX should keep the mean of the parents")
+	}
+}
+
+func TestBuildMixedRealMutation(t *testing.T) {
+	s, err := genotype.Build(func(bind genotype.BindFunc, ph *mixedStruct) (s genotype.Spec) {
+		s.Float64Chromosome(bind(&ph.X).Range(0, 10), bind(&ph.N)).
+			Mutate(mutation.Gaussian(1, 1))
+		return
+	})
+	require.NoError(t, err)
+
+	data := s.Make(1)
+	for range 100 {
+		s.Encode(&mixedStruct{5, 42}, data)
+		require.NoError(t, s.Mutate(data))
+
+		var ph mixedStruct
+		s.Decode(&ph, data)
+		assert.Equal(t, int32(42), ph.N)
+		assert.True(t, ph.X >= 0 && ph.X <= 10, "%f out of range", ph.X)
+	}
+}
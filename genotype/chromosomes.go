@@ -2,11 +2,13 @@ package genotype
 
 import (
 	"fmt"
+	"math"
 	"math/rand/v2"
 	"reflect"
-	"unsafe"
+	"slices"
 
 	"github.com/mbolis/genetta/crossover"
+	"github.com/mbolis/genetta/layout"
 	"github.com/mbolis/genetta/mutation"
 )
 
@@ -17,16 +19,46 @@ type Chromosome struct {
 	genes       []Gene
 	bytesLength int
 	bytesIndex  int
+	layout      layout.Chromosome
 
 	crossover crossover.Operator
 	mutate    mutation.Operator
 }
 
+func (c Chromosome) buildLayout() layout.Chromosome {
+	genes := make([]layout.Gene, len(c.genes))
+	for i, g := range c.genes {
+		genes[i] = layout.Gene{
+			Offset: g.byteIndex*8 + g.bitOffset,
+			Width:  g.bitWidth,
+			Kind:   g.type_,
+			Min:    math.Inf(-1),
+			Max:    math.Inf(1),
+			Gray:   g.gray,
+		}
+		switch {
+		case g.fixed:
+			genes[i].Kind = reflect.Uint64
+			genes[i].Min, genes[i].Max = 0, g.steps
+		case g.choices != nil:
+			genes[i].Kind = reflect.Uint64
+			genes[i].Min, genes[i].Max = g.min, g.max
+		case g.bounded:
+			genes[i].Min, genes[i].Max = g.min, g.max
+		}
+	}
+	slices.SortFunc(genes, func(a, b layout.Gene) int {
+		return a.Offset - b.Offset
+	})
+
+	return layout.Chromosome{Genes: genes, Bytes: c.bytesLength, Flags: uint(c.flags)}
+}
+
 type Flags uint
 
 const (
-	FlagDecimal Flags = 1 << iota
-	FlagPermutation
+	FlagDecimal     = Flags(layout.FlagDecimal)
+	FlagPermutation = Flags(layout.FlagPermutation)
 )
 
 func IntChromosome(components ...ChromosomeComponent) (c Chromosome) {
@@ -41,24 +73,48 @@ type ChromosomeComponent interface {
 	apply(*Chromosome)
 }
 
+// Randomize fills the bytes of the chromosome with random genes: random bits
+// for integer genes, a random permutation for permutation chromosomes, and
+// values within [0, 1) for floating point genes. Genes with a range are drawn
+// uniformly within it.
 func (c Chromosome) Randomize(data []byte) {
 	switch c.type_ {
 	case reflect.Int:
-		for i := range data {
-			data[i] = byte(rand.Uint())
-			// TODO enforce min-max
+		if c.flags&FlagPermutation != 0 {
+			for i, v := range rand.Perm(len(c.layout.Genes)) {
+				c.layout.Genes[i].SetUint(data, uint64(v))
+			}
+			return
 		}
-	case reflect.Float64:
 		for i := range data {
-			*(*float64)(unsafe.Pointer(&data[i])) = rand.Float64()
-			// TODO enforce min-max
+			data[i] = byte(rand.Uint())
 		}
-	case reflect.Float32:
-		for i := range data {
-			*(*float32)(unsafe.Pointer(&data[i])) = rand.Float32()
-			// TODO enforce min-max
+	case reflect.Float64, reflect.Float32:
+		for _, g := range c.layout.Genes {
+			switch g.Kind {
+			case reflect.Float32, reflect.Float64:
+				if math.IsInf(g.Min, 0) {
+					g.SetFloat(data, rand.Float64())
+				} else {
+					g.SetFloat(data, g.Clamp(g.Min+rand.Float64()*(g.Max-g.Min)))
+				}
+			default:
+				g.SetUint(data, rand.Uint64())
+			}
 		}
 	default:
 		panic(fmt.Sprintf("invalid chromosome type: %d", c.type_))
 	}
+
+	// random bits past a range are redrawn within it, so that all values are
+	// equally likely
+	c.repair(data)
+}
+
+// repair redraws the bounded integer and categorical genes whose bits, as set
+// by crossover, mutation or randomization, lie past their range.
+func (c Chromosome) repair(data []byte) {
+	for _, g := range c.genes {
+		g.repair(data)
+	}
 }
@@ -2,6 +2,8 @@ package genotype
 
 import (
 	"fmt"
+	"math"
+	"math/rand/v2"
 	"reflect"
 	"unsafe"
 )
@@ -9,9 +11,13 @@ import (
 type Gene struct {
 	type_ reflect.Kind
 	locus
+	bounds
+	gray    bool
+	choices []reflect.Value
 
 	phenotypeOffset uintptr
 	dynamic         dynamicPtr
+	dynamicType     reflect.Type
 	dynamicIndex    uintptr
 }
 type locus struct {
@@ -20,6 +26,101 @@ type locus struct {
 	bitWidth  int
 }
 
+// bounds restrict the values of a gene within [min, max]. Integer genes store
+// their distance from lo, the lower bound as stored by the phenotype: values
+// past span are redrawn by repair, and would wrap around when decoded anyway.
+// Fixed point genes store an unsigned integer, mapped linearly from [0, steps]
+// onto [min, max].
+type bounds struct {
+	bounded  bool
+	min, max float64
+
+	lo, span uint64
+	signed   bool
+	typeBits int
+
+	fixed bool
+	steps float64
+}
+
+// encode brings a value read from the phenotype within bounds, and returns
+// what the gene stores for it.
+func (b bounds) encode(k reflect.Kind, value uint64) uint64 {
+	if b.fixed {
+		if b.max == b.min {
+			return 0
+		}
+		v := b.clamp(floatValue(k, value))
+		return uint64(math.Round((v - b.min) / (b.max - b.min) * b.steps))
+	}
+
+	switch k {
+	case reflect.Float32, reflect.Float64:
+		return floatBits(k, b.clamp(floatValue(k, value)))
+	}
+
+	if b.signed {
+		shift := 64 - b.typeBits
+		v := int64(value<<shift) >> shift
+		v = min(max(v, int64(b.lo)), int64(b.lo+b.span))
+		return uint64(v) - b.lo
+	}
+	return min(max(value, b.lo), b.lo+b.span) - b.lo
+}
+
+// decode returns the value to write into the phenotype for what the gene
+// stores.
+func (b bounds) decode(k reflect.Kind, value uint64) uint64 {
+	if b.fixed {
+		v := b.min + float64(value)/b.steps*(b.max-b.min)
+		return floatBits(k, b.clamp(v))
+	}
+
+	switch k {
+	case reflect.Float32, reflect.Float64:
+		return b.encode(k, value)
+	}
+
+	if b.span < math.MaxUint64 {
+		value %= b.span + 1
+	}
+	return b.lo + value
+}
+
+func floatValue(k reflect.Kind, value uint64) float64 {
+	if k == reflect.Float32 {
+		return float64(math.Float32frombits(uint32(value)))
+	}
+	return math.Float64frombits(value)
+}
+
+func floatBits(k reflect.Kind, v float64) uint64 {
+	if k == reflect.Float32 {
+		return uint64(math.Float32bits(float32(v)))
+	}
+	return math.Float64bits(v)
+}
+
+func (b bounds) clamp(v float64) float64 {
+	if math.IsNaN(v) {
+		return b.min
+	}
+	return min(max(v, b.min), b.max)
+}
+
+// toGray converts a binary number to binary reflected Gray code.
+func toGray(v uint64) uint64 {
+	return v ^ v>>1
+}
+
+// fromGray converts binary reflected Gray code back to a binary number.
+func fromGray(v uint64) uint64 {
+	for shift := 1; shift < 64; shift <<= 1 {
+		v ^= v >> shift
+	}
+	return v
+}
+
 type dynamicPtr func(ptr, i uintptr) uintptr
 
 type integer interface {
@@ -50,6 +151,11 @@ func (g Gene) Encode(ptr unsafe.Pointer, data []byte) {
 		position = unsafe.Pointer(g.dynamic(uintptr(position), g.dynamicIndex))
 	}
 
+	if g.choices != nil {
+		g.encodeChoice(position, data)
+		return
+	}
+
 	var value uint64
 	switch g.type_ {
 	case reflect.Bool:
@@ -80,6 +186,12 @@ func (g Gene) Encode(ptr unsafe.Pointer, data []byte) {
 		read[float64](position, &value)
 	}
 
+	if g.bounded {
+		value = g.encode(g.type_, value)
+	}
+	if g.gray {
+		value = toGray(value &^ (mask << g.bitWidth))
+	}
 	g.write(data, value)
 }
 func read[T any](position unsafe.Pointer, value *uint64) {
@@ -92,7 +204,18 @@ func (g Gene) Decode(ptr unsafe.Pointer, data []byte) {
 		position = unsafe.Pointer(g.dynamic(uintptr(position), g.dynamicIndex))
 	}
 
+	if g.choices != nil {
+		g.decodeChoice(position, data)
+		return
+	}
+
 	value := g.read(data)
+	if g.gray {
+		value = fromGray(value)
+	}
+	if g.bounded {
+		value = g.decode(g.type_, value)
+	}
 
 	switch g.type_ {
 	case reflect.Bool:
@@ -127,6 +250,60 @@ func write[T any](position unsafe.Pointer, value uint64) {
 	*(*T)(position) = *(*T)(unsafe.Pointer(&value))
 }
 
+// repair replaces the value of a bounded integer or categorical gene with one
+// drawn uniformly within bounds, if it lies past them.
+func (g Gene) repair(data []byte) {
+	if !g.bounded || g.fixed || g.span >= ^(mask<<g.bitWidth) {
+		return
+	}
+	if g.choices == nil && (g.type_ == reflect.Float32 || g.type_ == reflect.Float64) {
+		return
+	}
+
+	value := g.read(data)
+	if g.gray {
+		value = fromGray(value)
+	}
+	if value <= g.span {
+		return
+	}
+
+	value = rand.Uint64N(g.span + 1)
+	if g.gray {
+		value = toGray(value)
+	}
+	g.write(data, value)
+}
+
+// encodeChoice stores the index of the value of a categorical gene, or 0 if
+// it is not among its choices.
+func (g Gene) encodeChoice(position unsafe.Pointer, data []byte) {
+	v := reflect.NewAt(g.choices[0].Type(), position).Elem()
+
+	var value uint64
+	for i, c := range g.choices {
+		if reflect.DeepEqual(v.Interface(), c.Interface()) {
+			value = uint64(i)
+			break
+		}
+	}
+
+	if g.gray {
+		value = toGray(value)
+	}
+	g.write(data, value)
+}
+
+func (g Gene) decodeChoice(position unsafe.Pointer, data []byte) {
+	value := g.read(data)
+	if g.gray {
+		value = fromGray(value)
+	}
+	value = g.decode(reflect.Uint64, value)
+
+	reflect.NewAt(g.choices[0].Type(), position).Elem().Set(g.choices[value])
+}
+
 const mask = ^uint64(0)
 
 func (l locus) read(data []byte) (value uint64) {
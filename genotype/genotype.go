@@ -1,9 +1,11 @@
 package genotype
 
 import (
-	"fmt"
 	"reflect"
 	"unsafe"
+
+	"github.com/mbolis/genetta/crossover"
+	"github.com/mbolis/genetta/mutation"
 )
 
 type Schema[T any] struct {
@@ -20,6 +22,22 @@ func New[T any](chromosomes ...Chromosome) (s Schema[T]) {
 	return
 }
 
+// Clone returns a copy of the schema whose operators can be used concurrently
+// with the original ones.
+func (s Schema[T]) Clone() Schema[T] {
+	chromosomes := make([]Chromosome, len(s.chromosomes))
+	for i, c := range s.chromosomes {
+		if c.crossover != nil {
+			c.crossover = crossover.Clone(c.crossover)
+		}
+		if c.mutate != nil {
+			c.mutate = mutation.Clone(c.mutate)
+		}
+		chromosomes[i] = c
+	}
+	return Schema[T]{chromosomes, s.sizeInBytes}
+}
+
 func (s Schema[T]) Size() int {
 	return s.sizeInBytes
 }
@@ -34,28 +52,27 @@ func (s Schema[T]) Make(popSize int) []byte {
 	return make([]byte, popSize*s.sizeInBytes)
 }
 
+// Init returns a phenotype ready to be decoded into: the phenotype itself, if
+// a slice, and any slice it holds are allocated to fit their genes.
 func (s Schema[T]) Init() (t T) {
-	if any(t) != nil {
-		return
+	types := map[uintptr]reflect.Type{}
+	lens := map[uintptr]int{}
+	if tt := reflect.TypeFor[T](); tt.Kind() == reflect.Slice {
+		types[0] = tt
 	}
-
-	tt := reflect.TypeFor[T]()
-	switch tt.Kind() {
-	case reflect.Slice:
-		cells := -1
-		for _, c := range s.chromosomes {
-			for _, g := range c.genes {
-				if g.byteIndex > cells {
-					cells = g.byteIndex
-				}
+	for _, c := range s.chromosomes {
+		for _, g := range c.genes {
+			if g.dynamic != nil {
+				types[g.phenotypeOffset] = g.dynamicType
+				n := int(g.dynamicIndex/g.dynamicType.Elem().Size()) + 1
+				lens[g.phenotypeOffset] = max(lens[g.phenotypeOffset], n)
 			}
 		}
-		cells++
-		// FIXME broken for complex numbers!
-		t = reflect.MakeSlice(tt, cells, cells).Interface().(T)
+	}
 
-	default:
-		panic(fmt.Sprintf("unrecognized type: %s", tt))
+	for offset, st := range types {
+		slice := reflect.NewAt(st, unsafe.Add(unsafe.Pointer(&t), offset)).Elem()
+		slice.Set(reflect.MakeSlice(st, lens[offset], lens[offset]))
 	}
 	return
 }
@@ -77,7 +94,7 @@ func (s Schema[T]) Decode(ptr *T, data []byte) {
 
 func (s Schema[T]) Randomize(data []byte) {
 	for _, c := range s.chromosomes {
-		c.Randomize(data)
+		c.Randomize(data[c.bytesIndex : c.bytesIndex+c.bytesLength])
 	}
 }
 
@@ -86,6 +103,12 @@ func (s Schema[T]) Crossover(mom, dad, child1, child2 []byte) error {
 		i1 := c.bytesIndex
 		i2 := i1 + c.bytesLength
 
+		if c.crossover == nil {
+			copy(child1[i1:i2], mom[i1:i2])
+			copy(child2[i1:i2], dad[i1:i2])
+			continue
+		}
+
 		err := c.crossover.Crossover(
 			mom[i1:i2], dad[i1:i2],
 			child1[i1:i2], child2[i1:i2],
@@ -93,6 +116,8 @@ func (s Schema[T]) Crossover(mom, dad, child1, child2 []byte) error {
 		if err != nil {
 			return err
 		}
+		c.repair(child1[i1:])
+		c.repair(child2[i1:])
 	}
 	return nil
 }
@@ -100,10 +125,15 @@ func (s Schema[T]) Crossover(mom, dad, child1, child2 []byte) error {
 func (s Schema[T]) Mutate(genotypes ...[]byte) error {
 	for _, g := range genotypes {
 		for _, c := range s.chromosomes {
+			if c.mutate == nil {
+				continue
+			}
+
 			err := c.mutate.Mutate(g[c.bytesIndex : c.bytesIndex+c.bytesLength])
 			if err != nil {
 				return err
 			}
+			c.repair(g[c.bytesIndex:])
 		}
 	}
 	return nil
@@ -0,0 +1,271 @@
+package genotype
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// FromStruct derives the schema of a struct from the genetta tags of its
+// fields, comma separated lists of:
+//   - chromosome=name: the chromosome of the field; fields naming none share
+//     an unnamed one
+//   - bits=n: the width of the field, see GeneSpec.Bits
+//   - min=x,max=y: the range of the field, see GeneSpec.Range, or of fixed
+//     point floating point fields with a width, see GeneSpec.Between
+//   - oneof=a|b|c: the values of a categorical field, see GeneSpec.OneOf; it
+//     does not go with min and max
+//   - len=n: the length of a slice field, required, see GeneSpec.Len
+//   - gray: store the field in Gray code, see GeneSpec.Gray
+//
+// Fields tagged "-" and unexported fields are skipped. Chromosomes come in the
+// order their first field is declared: those made only of floating point
+// fields with no width are real-valued, the others integer chromosomes.
+func FromStruct[T any]() (schema Schema[T], err error) {
+	t := reflect.TypeFor[T]()
+	if t.Kind() != reflect.Struct {
+		err = fmt.Errorf("cannot derive a schema from %v: not a struct", t)
+		return
+	}
+
+	var chromosomes []*taggedChromosome
+	byName := map[string]*taggedChromosome{}
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag, ok := f.Tag.Lookup("genetta")
+		if tag == "-" || !f.IsExported() {
+			continue
+		}
+
+		var tf taggedField
+		if tf, err = parseTag(f, tag, ok); err != nil {
+			return
+		}
+
+		c := byName[tf.chromosome]
+		if c == nil {
+			c = &taggedChromosome{realValued: true}
+			byName[tf.chromosome] = c
+			chromosomes = append(chromosomes, c)
+		}
+		c.add(tf)
+	}
+
+	for _, c := range chromosomes {
+		if err = c.check(); err != nil {
+			return
+		}
+	}
+
+	return Build(func(bind BindFunc, ph *T) (s Spec) {
+		for _, c := range chromosomes {
+			genes := make([]*GeneSpec, len(c.fields))
+			for i, f := range c.fields {
+				genes[i] = f.bind(bind, unsafe.Add(unsafe.Pointer(ph), f.Offset), c.realValued)
+			}
+
+			switch {
+			case !c.realValued:
+				s.IntChromosome(genes...)
+			case c.double:
+				s.Float64Chromosome(genes...)
+			default:
+				s.Float32Chromosome(genes...)
+			}
+		}
+		return
+	})
+}
+
+type taggedChromosome struct {
+	fields     []taggedField
+	realValued bool
+	double     bool
+}
+
+func (c *taggedChromosome) add(f taggedField) {
+	c.fields = append(c.fields, f)
+
+	switch geneType(f.Type).Kind() {
+	case reflect.Float64:
+		c.double = true
+		c.realValued = c.realValued && f.bits == 0 && f.oneOf == nil
+	case reflect.Float32:
+		c.realValued = c.realValued && f.bits == 0 && f.oneOf == nil
+	default:
+		c.realValued = false
+	}
+}
+
+// check fails on fields whose options do not fit their type, or the kind of
+// the chromosome, before binding them would panic.
+func (c *taggedChromosome) check() error {
+	for _, f := range c.fields {
+		if err := f.check(c.realValued); err != nil {
+			return fmt.Errorf("field %s: %w", f.Name, err)
+		}
+	}
+	return nil
+}
+
+type taggedField struct {
+	reflect.StructField
+
+	chromosome string
+	bits, len  int
+	ranged     bool
+	min, max   float64
+	oneOf      []any
+	gray       bool
+}
+
+// check fails on options that do not fit the type of the field, and on
+// floating point fields that would be stored as raw bits truncated to their
+// width.
+func (f taggedField) check(realValued bool) error {
+	t := geneType(f.Type)
+	switch isSlice := f.Type.Kind() == reflect.Slice; {
+	case isSlice && f.len <= 0:
+		return errors.New("slice fields need a positive len")
+	case !isSlice && f.len != 0:
+		return fmt.Errorf("invalid length for %v: %d", f.Type, f.len)
+	}
+	if err := checkWidth(t, f.bits); err != nil {
+		return err
+	}
+	if f.ranged {
+		if !realValued && isFloat(t.Kind()) {
+			return checkFixedRange(t, f.min, f.max)
+		}
+		return checkRange(t, f.min, f.max)
+	}
+	if f.bits > 0 && f.oneOf == nil && isFloat(t.Kind()) {
+		return errors.New("fixed point fields need min and max")
+	}
+	return nil
+}
+
+func (f taggedField) bind(bind BindFunc, ptr unsafe.Pointer, realValued bool) *GeneSpec {
+	g := bind(reflect.NewAt(f.Type, ptr).Interface())
+	if f.len > 0 {
+		g.Len(f.len)
+	}
+	if f.oneOf != nil {
+		g.OneOf(f.oneOf...)
+	}
+	if f.ranged {
+		if !realValued && isFloat(g.type_.Kind()) {
+			g.Between(f.min, f.max)
+		} else {
+			g.Range(f.min, f.max)
+		}
+	}
+	if f.bits > 0 {
+		g.Bits(f.bits)
+	}
+	if f.gray {
+		g.Gray()
+	}
+	return g
+}
+
+func parseTag(f reflect.StructField, tag string, ok bool) (tf taggedField, err error) {
+	tf.StructField = f
+	if !ok || tag == "" {
+		return
+	}
+
+	var hasMin, hasMax bool
+	for _, option := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(option, "=")
+		switch key {
+		case "chromosome":
+			tf.chromosome = value
+		case "bits":
+			tf.bits, err = strconv.Atoi(value)
+		case "len":
+			tf.len, err = strconv.Atoi(value)
+		case "min":
+			tf.min, err = strconv.ParseFloat(value, 64)
+			hasMin = true
+		case "max":
+			tf.max, err = strconv.ParseFloat(value, 64)
+			hasMax = true
+		case "oneof":
+			tf.oneOf, err = parseChoices(geneType(f.Type), strings.Split(value, "|"))
+		case "gray":
+			tf.gray = true
+		default:
+			err = fmt.Errorf("unknown option %q", key)
+		}
+		if err != nil {
+			err = fmt.Errorf("field %s: %w", f.Name, err)
+			return
+		}
+	}
+
+	switch {
+	case hasMin != hasMax:
+		err = fmt.Errorf("field %s: min and max go together", f.Name)
+	case hasMin && tf.oneOf != nil:
+		err = fmt.Errorf("field %s: oneof does not go with min and max", f.Name)
+	}
+	tf.ranged = hasMin && hasMax
+	return
+}
+
+func parseChoices(t reflect.Type, values []string) (choices []any, err error) {
+	choices = make([]any, len(values))
+	for i, s := range values {
+		v := reflect.New(t).Elem()
+		switch {
+		case t.Kind() == reflect.String:
+			v.SetString(s)
+		case t.Kind() == reflect.Bool:
+			var b bool
+			b, err = strconv.ParseBool(s)
+			v.SetBool(b)
+		case v.CanInt():
+			var n int64
+			n, err = strconv.ParseInt(s, 0, t.Bits())
+			v.SetInt(n)
+		case v.CanUint():
+			var n uint64
+			n, err = strconv.ParseUint(s, 0, t.Bits())
+			v.SetUint(n)
+		case v.CanFloat():
+			var x float64
+			x, err = strconv.ParseFloat(s, t.Bits())
+			v.SetFloat(x)
+		default:
+			err = fmt.Errorf("cannot parse values of type %v", t)
+		}
+		if err != nil {
+			return
+		}
+		choices[i] = v.Interface()
+	}
+	return
+}
+
+// geneType returns the type of the genes of a field, looking through arrays
+// and slices, and splitting complex numbers into their parts.
+func geneType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Array || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Complex64:
+		return reflect.TypeFor[float32]()
+	case reflect.Complex128:
+		return reflect.TypeFor[float64]()
+	}
+	return t
+}
+
+func isFloat(k reflect.Kind) bool {
+	return k == reflect.Float32 || k == reflect.Float64
+}
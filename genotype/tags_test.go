@@ -0,0 +1,185 @@
+package genotype_test
+
+import (
+	"testing"
+
+	"github.com/mbolis/genetta/genotype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type config struct {
+	Lat     float64  `genetta:"chromosome=geo,min=-90,max=90"`
+	Radius  int      `genetta:"bits=6,min=0,max=50"`
+	Alpha   float32  `genetta:"bits=10,min=0,max=1"`
+	Lon     float64  `genetta:"chromosome=geo,min=-180,max=180"`
+	Mode    string   `genetta:"oneof=fast|slow|auto"`
+	Weights [4]uint8 `genetta:"bits=4,gray"`
+	Levels  []int16  `genetta:"chromosome=levels,len=3,oneof=-1|0|1"`
+	Name    string   `genetta:"-"`
+	seed    uint32
+}
+
+func TestFromStruct(t *testing.T) {
+	s, err := genotype.FromStruct[config]()
+	require.NoError(t, err)
+
+	expected, err := genotype.Build(func(bind genotype.BindFunc, ph *config) (s genotype.Spec) {
+		s.Float64Chromosome(bind(&ph.Lat).Range(-90, 90), bind(&ph.Lon).Range(-180, 180))
+		s.IntChromosome(
+			bind(&ph.Radius).Range(0, 50).Bits(6),
+			bind(&ph.Alpha).Between(0, 1).Bits(10),
+			bind(&ph.Mode).OneOf("fast", "slow", "auto"),
+			bind(&ph.Weights).Bits(4).Gray(),
+		)
+		s.IntChromosome(bind(&ph.Levels).Len(3).OneOf(int16(-1), int16(0), int16(1)))
+		return
+	})
+	require.NoError(t, err)
+	assert.Equal(t, expected.Size(), s.Size())
+
+	ph := config{
+		Lat:     45,
+		Radius:  42,
+		Alpha:   0.5,
+		Lon:     -120,
+		Mode:    "auto",
+		Weights: [4]uint8{1, 2, 3, 15},
+		Levels:  []int16{1, -1, 0},
+		Name:    "ignored",
+		seed:    0xdeadbeef,
+	}
+	data, expectedData := s.Make(1), expected.Make(1)
+	s.Encode(&ph, data)
+	expected.Encode(&ph, expectedData)
+	assert.Equal(t, expectedData, data)
+
+	decoded := s.Init()
+	assert.Len(t, decoded.Levels, 3)
+	s.Decode(&decoded, data)
+	assert.Equal(t, config{45, 42, float32(512.0 / 1023), -120, "auto", [4]uint8{1, 2, 3, 15}, []int16{1, -1, 0}, "", 0}, decoded)
+
+	for range 1000 {
+		s.Randomize(data)
+		s.Decode(&decoded, data)
+		assert.True(t, decoded.Lat >= -90 && decoded.Lat <= 90)
+		assert.True(t, decoded.Lon >= -180 && decoded.Lon <= 180)
+		assert.True(t, decoded.Radius >= 0 && decoded.Radius <= 50)
+		assert.Contains(t, []string{"fast", "slow", "auto"}, decoded.Mode)
+	}
+}
+
+func TestFromStructErrors(t *testing.T) {
+	for name, build := range map[string]func() error{
+		"not a struct": func() error {
+			_, err := genotype.FromStruct[[]int]()
+			return err
+		},
+		"unknown option": func() error {
+			_, err := genotype.FromStruct[struct {
+				X int `genetta:"width=3"`
+			}]()
+			return err
+		},
+		"invalid width": func() error {
+			_, err := genotype.FromStruct[struct {
+				X int `genetta:"bits=three"`
+			}]()
+			return err
+		},
+		"min without max": func() error {
+			_, err := genotype.FromStruct[struct {
+				X int `genetta:"min=3"`
+			}]()
+			return err
+		},
+		"invalid choice": func() error {
+			_, err := genotype.FromStruct[struct {
+				X uint8 `genetta:"oneof=1|2|300"`
+			}]()
+			return err
+		},
+		"unbounded fixed point": func() error {
+			_, err := genotype.FromStruct[struct {
+				X float64 `genetta:"bits=8"`
+			}]()
+			return err
+		},
+		"range too wide": func() error {
+			_, err := genotype.FromStruct[struct {
+				X int8 `genetta:"min=0,max=300"`
+			}]()
+			return err
+		},
+		"fractional range": func() error {
+			_, err := genotype.FromStruct[struct {
+				X int `genetta:"min=0,max=2.5"`
+			}]()
+			return err
+		},
+		"width too wide": func() error {
+			_, err := genotype.FromStruct[struct {
+				X uint8 `genetta:"bits=12"`
+			}]()
+			return err
+		},
+		"negative width": func() error {
+			_, err := genotype.FromStruct[struct {
+				X uint8 `genetta:"bits=-1"`
+			}]()
+			return err
+		},
+		"fixed point too wide": func() error {
+			_, err := genotype.FromStruct[struct {
+				X float32 `genetta:"bits=40,min=0,max=1"`
+			}]()
+			return err
+		},
+		"bounded string": func() error {
+			_, err := genotype.FromStruct[struct {
+				X string `genetta:"min=0,max=1"`
+			}]()
+			return err
+		},
+		"length of an array": func() error {
+			_, err := genotype.FromStruct[struct {
+				X [2]int `genetta:"len=3"`
+			}]()
+			return err
+		},
+		"slice without length": func() error {
+			_, err := genotype.FromStruct[struct {
+				X []int `genetta:"bits=4"`
+			}]()
+			return err
+		},
+		"untagged slice": func() error {
+			_, err := genotype.FromStruct[struct {
+				X []int
+			}]()
+			return err
+		},
+		"negative length": func() error {
+			_, err := genotype.FromStruct[struct {
+				X []int `genetta:"len=-1"`
+			}]()
+			return err
+		},
+		"choices within a range": func() error {
+			_, err := genotype.FromStruct[struct {
+				X int `genetta:"oneof=1|2|3,min=0,max=9"`
+			}]()
+			return err
+		},
+		"string without choices": func() error {
+			_, err := genotype.FromStruct[struct {
+				X string
+			}]()
+			return err
+		},
+	} {
+		t.Run("should fail on "+name, func(t *testing.T) {
+			assert.Error(t, build())
+		})
+	}
+}
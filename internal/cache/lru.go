@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+)
+
+// LRU maps genotypes to fitness values, evicting the least recently used
+// entry once full. It is safe for concurrent use.
+type LRU struct {
+	capacity int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   list.List
+}
+
+type entry struct {
+	key     string
+	fitness float64
+}
+
+func NewLRU(capacity int) *LRU {
+	return &LRU{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element, capacity),
+	}
+}
+
+func (c *LRU) Get(genotype []byte) (float64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[string(genotype)]
+	if !ok {
+		return 0, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*entry).fitness, true
+}
+
+func (c *LRU) Put(genotype []byte, fitness float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[string(genotype)]; ok {
+		e.Value.(*entry).fitness = fitness
+		c.order.MoveToFront(e)
+		return
+	}
+
+	if c.order.Len() >= c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*entry).key)
+	}
+
+	key := string(genotype)
+	c.entries[key] = c.order.PushFront(&entry{key, fitness})
+}
+
+func (c *LRU) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
@@ -0,0 +1,43 @@
+package cache_test
+
+import (
+	"testing"
+
+	"github.com/mbolis/genetta/internal/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRU(t *testing.T) {
+	c := cache.NewLRU(2)
+
+	_, ok := c.Get([]byte{1})
+	assert.False(t, ok)
+
+	c.Put([]byte{1}, 1)
+	c.Put([]byte{2}, 2)
+	f, ok := c.Get([]byte{1})
+	assert.True(t, ok)
+	assert.Equal(t, 1.0, f)
+
+	// 2 is now the least recently used
+	c.Put([]byte{3}, 3)
+	assert.Equal(t, 2, c.Len())
+	_, ok = c.Get([]byte{2})
+	assert.False(t, ok)
+
+	f, ok = c.Get([]byte{3})
+	assert.True(t, ok)
+	assert.Equal(t, 3.0, f)
+
+	t.Run("should not be affected by changes to the genotype", func(t *testing.T) {
+		g := []byte{4}
+		c.Put(g, 4)
+		g[0] = 5
+
+		_, ok := c.Get([]byte{5})
+		assert.False(t, ok)
+		f, ok := c.Get([]byte{4})
+		assert.True(t, ok)
+		assert.Equal(t, 4.0, f)
+	})
+}
@@ -34,6 +34,13 @@ type pool[W any] struct {
 	wgroup sync.WaitGroup
 }
 
+// Resetter can be implemented by worker contexts holding state that must
+// survive across batches, such as scratch buffers: on Resume, Reset is called
+// in place of replacing the context with its zero value.
+type Resetter interface {
+	Reset()
+}
+
 const stopSignal = math.MinInt
 
 var (
@@ -73,8 +80,11 @@ func New[W any](nWorkers, bufferSize int, work func(*W, int)) (Pool[W], error) {
 			}
 		parked:
 			for range p.resumeQ {
-				p.wgroup.Add(1)
-				p.workers[i] = emptyCtx
+				if r, ok := any(&p.workers[i]).(Resetter); ok {
+					r.Reset()
+				} else {
+					p.workers[i] = emptyCtx
+				}
 				goto running
 			}
 		}(i)
@@ -121,6 +131,7 @@ func (p *pool[W]) Resume() {
 	}
 	p.status = StatusRunning
 
+	p.wgroup.Add(len(p.workers))
 	for range p.workers {
 		p.resumeQ <- struct{}{}
 	}
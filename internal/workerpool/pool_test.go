@@ -19,6 +19,22 @@ func (t *testWorker) work(i int) {
 	t.sum += i
 }
 
+type resettableWorker struct {
+	batches int
+	testWorker
+}
+
+func (r *resettableWorker) work(i int) {
+	if r.count == 0 {
+		r.batches++
+	}
+	r.testWorker.work(i)
+}
+
+func (r *resettableWorker) Reset() {
+	r.testWorker = testWorker{}
+}
+
 const (
 	nWorkers = 8
 	jobs     = 1_000_000
@@ -82,4 +98,30 @@ func TestPool(t *testing.T) {
 		}
 		assert.Equal(t, expectedSum, actualSum)
 	})
+
+	t.Run("should reset worker contexts implementing Resetter", func(t *testing.T) {
+		pool, err := workerpool.New(nWorkers, jobs, (*resettableWorker).work)
+		assert.NoError(t, err)
+		defer pool.Close()
+
+		var expectedSum int
+		for i := range jobs {
+			pool.Offer(i)
+			expectedSum += i
+		}
+		pool.Wait()
+
+		for i := range jobs {
+			pool.Offer(i)
+		}
+		pool.Resume()
+		pool.Wait()
+
+		var actualSum int
+		for _, w := range pool.All() {
+			actualSum += w.sum
+			assert.Equal(t, 2, w.batches)
+		}
+		assert.Equal(t, expectedSum, actualSum)
+	})
 }
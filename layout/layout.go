@@ -0,0 +1,125 @@
+// Package layout describes how genes are laid out within a chromosome, for
+// the genetic operators that need to know it.
+package layout
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// Gene tells where a gene lies within its chromosome. Bits are numbered from
+// the least significant bit of the first byte, so bit n is bit n%8 of byte
+// n/8. Min and Max bound the values of the gene, and are infinite when it is
+// unbounded. Bounded integer genes hold their distance from Min. Gray genes
+// hold their integer value in binary reflected Gray code.
+type Gene struct {
+	Offset int
+	Width  int
+	Kind   reflect.Kind
+
+	Min  float64
+	Max  float64
+	Gray bool
+}
+
+// Uint reads the value of an integer gene out of the chromosome.
+func (g Gene) Uint(data []byte) (v uint64) {
+	for bit := 0; bit < g.Width; {
+		pos := g.Offset + bit
+		i, shift := pos/8, pos%8
+		n := min(8-shift, g.Width-bit)
+
+		v |= uint64(data[i]>>shift) & (1<<n - 1) << bit
+		bit += n
+	}
+	return
+}
+
+// SetUint writes the value of an integer gene into the chromosome, truncating
+// it to the width of the gene.
+func (g Gene) SetUint(data []byte, v uint64) {
+	for bit := 0; bit < g.Width; {
+		pos := g.Offset + bit
+		i, shift := pos/8, pos%8
+		n := min(8-shift, g.Width-bit)
+
+		mask := byte(1<<n-1) << shift
+		data[i] = data[i]&^mask | byte(v>>bit)<<shift&mask
+		bit += n
+	}
+}
+
+// Float reads the value of a floating point gene out of the chromosome.
+func (g Gene) Float(data []byte) float64 {
+	i := g.Offset / 8
+	switch g.Kind {
+	case reflect.Float32:
+		return float64(math.Float32frombits(binary.NativeEndian.Uint32(data[i:])))
+	case reflect.Float64:
+		return math.Float64frombits(binary.NativeEndian.Uint64(data[i:]))
+	}
+	panic(fmt.Sprintf("not a floating point gene: %v", g.Kind))
+}
+
+// SetFloat writes the value of a floating point gene into the chromosome.
+func (g Gene) SetFloat(data []byte, v float64) {
+	i := g.Offset / 8
+	switch g.Kind {
+	case reflect.Float32:
+		binary.NativeEndian.PutUint32(data[i:], math.Float32bits(float32(v)))
+	case reflect.Float64:
+		binary.NativeEndian.PutUint64(data[i:], math.Float64bits(v))
+	default:
+		panic(fmt.Sprintf("not a floating point gene: %v", g.Kind))
+	}
+}
+
+// Clamp brings v within the bounds of the gene.
+func (g Gene) Clamp(v float64) float64 {
+	return min(max(v, g.Min), g.Max)
+}
+
+// Flags qualify the chromosomes operators are applied to, beyond the kind of
+// their genes.
+const (
+	FlagDecimal uint = 1 << iota
+	// FlagPermutation marks chromosomes whose genes always hold a permutation
+	// of 0..n-1, n being the number of genes.
+	FlagPermutation
+)
+
+// Chromosome lists the genes of a chromosome, sorted by offset. Bits that do
+// not belong to any gene are padding.
+type Chromosome struct {
+	Genes []Gene
+	Bytes int
+	Flags uint
+}
+
+// Boundaries returns the offsets of all genes but the first, where a
+// chromosome can be cut without splitting any gene.
+func (c Chromosome) Boundaries() []int {
+	if len(c.Genes) == 0 {
+		return nil
+	}
+
+	boundaries := make([]int, len(c.Genes)-1)
+	for i, g := range c.Genes[1:] {
+		boundaries[i] = g.Offset
+	}
+	return boundaries
+}
+
+// FloatGenes returns the floating point genes of the chromosome, leaving out
+// any integer gene declared along with them.
+func (c Chromosome) FloatGenes() []Gene {
+	genes := make([]Gene, 0, len(c.Genes))
+	for _, g := range c.Genes {
+		if g.Kind == reflect.Float32 || g.Kind == reflect.Float64 {
+			genes = append(genes, g)
+		}
+	}
+	return genes
+}
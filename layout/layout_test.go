@@ -0,0 +1,41 @@
+package layout_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mbolis/genetta/layout"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUint(t *testing.T) {
+	data := []byte{0xff, 0xff, 0xff, 0xff}
+	g := layout.Gene{Offset: 5, Width: 12, Kind: reflect.Uint16}
+
+	g.SetUint(data, 0x2bc)
+	assert.Equal(t, uint64(0x2bc), g.Uint(data))
+	assert.Equal(t, []byte{0x9f, 0x57, 0xfe, 0xff}, data, "bits around the gene should be left alone")
+
+	g.SetUint(data, 0x1234)
+	assert.Equal(t, uint64(0x234), g.Uint(data), "values should be truncated to the width")
+}
+
+func TestFloat(t *testing.T) {
+	data := make([]byte, 12)
+	g32 := layout.Gene{Offset: 0, Width: 32, Kind: reflect.Float32}
+	g64 := layout.Gene{Offset: 32, Width: 64, Kind: reflect.Float64}
+
+	g32.SetFloat(data, 1.5)
+	g64.SetFloat(data, -0.1)
+	assert.Equal(t, 1.5, g32.Float(data))
+	assert.Equal(t, -0.1, g64.Float(data))
+}
+
+func TestBoundaries(t *testing.T) {
+	c := layout.Chromosome{Genes: []layout.Gene{
+		{Offset: 0, Width: 3},
+		{Offset: 3, Width: 3},
+		{Offset: 8, Width: 8},
+	}}
+	assert.Equal(t, []int{3, 8}, c.Boundaries())
+}
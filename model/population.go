@@ -1,17 +1,45 @@
 package model
 
 import (
+	"cmp"
 	"math"
+	"slices"
 	"sort"
 
 	"github.com/mbolis/genetta/genotype"
 )
 
+// Direction tells whether fitness values are to be maximized or minimized.
+type Direction int
+
+const (
+	Maximize Direction = iota
+	Minimize
+)
+
+// Better reports whether fitness a is strictly better than fitness b.
+func (d Direction) Better(a, b float64) bool {
+	if d == Minimize {
+		return a < b
+	}
+	return a > b
+}
+
+// Compare returns a positive number if fitness a is better than fitness b, a
+// negative one if it is worse, and 0 otherwise.
+func (d Direction) Compare(a, b float64) int {
+	if d == Minimize {
+		return cmp.Compare(b, a)
+	}
+	return cmp.Compare(a, b)
+}
+
 type Genomes struct {
 	size          int
 	chromosomeLen int
 	genotype      []byte
 
+	direction    Direction
 	fitness      []float64
 	totalFitness float64
 
@@ -29,10 +57,12 @@ func (g GenomesSortDesc) Len() int {
 	return g.size
 }
 func (g GenomesSortDesc) Less(i, j int) bool {
-	return g.fitness[i] > g.fitness[j]
+	return g.direction.Better(g.fitness[i], g.fitness[j])
 }
 func (g GenomesSortDesc) Swap(i, j int) {
 	g.fitness[i], g.fitness[j] = g.fitness[j], g.fitness[i]
+	g.fittest = swapped(g.fittest, i, j)
+	g.worst = swapped(g.worst, i, j)
 
 	i0 := i * g.chromosomeLen
 	j0 := j * g.chromosomeLen
@@ -43,6 +73,17 @@ func (g GenomesSortDesc) Swap(i, j int) {
 	}
 }
 
+// swapped returns where index k ends up once i and j are swapped.
+func swapped(k, i, j int) int {
+	switch k {
+	case i:
+		return j
+	case j:
+		return i
+	}
+	return k
+}
+
 func (g Genomes) NIndividuals() int {
 	return g.size
 }
@@ -52,30 +93,153 @@ func (g Genomes) Genotype(i int) []byte {
 	return g.genotype[o : o+g.chromosomeLen]
 }
 
+func (g Genomes) Direction() Direction {
+	return g.direction
+}
+
+// SetDirection changes the direction in which fitness is optimized.
+func (g *Genomes) SetDirection(d Direction) {
+	if d == g.direction {
+		return
+	}
+
+	g.direction = d
+	g.fittest, g.worst = g.worst, g.fittest
+	g.isSorted = false
+}
+
 func (g Genomes) Fitness(i int) float64 {
 	return g.fitness[i]
 }
-func (g *Genomes) SetFitness(i int, f float64) { // XXX better to produce aggregates in the parallel code, then set
-	g.totalFitness += f - g.fitness[i]
+
+// SetFitness sets the fitness of individual i, and keeps the aggregates up to
+// date. Overwriting the fittest or the worst individual with a less extreme
+// value triggers a scan of the whole population, so it should only happen once
+// all fitness values have been set since the last Reset.
+func (g *Genomes) SetFitness(i int, f float64) {
+	previous := g.fitness[i]
+	g.totalFitness += f - previous
 	g.fitness[i] = f
+	g.isSorted = false
 
-	if g.worst < 0 || f < g.fitness[g.worst] {
+	if (i == g.worst && g.direction.Better(f, previous)) || (i == g.fittest && g.direction.Better(previous, f)) {
+		g.rescan()
+		return
+	}
+
+	if g.worst < 0 || g.direction.Better(g.fitness[g.worst], f) {
 		g.worst = i
 	}
-	if g.fittest < 0 || f > g.fitness[g.fittest] {
+	if g.fittest < 0 || g.direction.Better(f, g.fitness[g.fittest]) {
 		g.fittest = i
 	}
 }
 
+// Reset clears the fitness values and their aggregates.
+func (g *Genomes) Reset() {
+	clear(g.fitness)
+	g.totalFitness = 0
+	g.fittest = -1
+	g.worst = -1
+	g.isSorted = false
+}
+
+func (g *Genomes) rescan() {
+	var a Aggregate
+	for i, f := range g.fitness {
+		a.Add(i, f)
+	}
+
+	g.fittest, g.worst = a.argMax, a.argMin
+	if g.direction == Minimize {
+		g.fittest, g.worst = g.worst, g.fittest
+	}
+}
+
+// StoreFitness sets the fitness of individual i without touching the
+// aggregates, so it can be called concurrently on distinct individuals.
+// Aggregates are then collected with an Aggregate and applied with Merge.
+func (g Genomes) StoreFitness(i int, f float64) {
+	g.fitness[i] = f
+}
+
+// Aggregate accumulates the fitness aggregates of a subset of individuals.
+type Aggregate struct {
+	n            int
+	argMax       int
+	argMin       int
+	maxFitness   float64
+	minFitness   float64
+	totalFitness float64
+}
+
+func (a *Aggregate) Add(i int, f float64) {
+	if a.n == 0 || f > a.maxFitness {
+		a.argMax = i
+		a.maxFitness = f
+	}
+	if a.n == 0 || f < a.minFitness {
+		a.argMin = i
+		a.minFitness = f
+	}
+	a.totalFitness += f
+	a.n++
+}
+
+// Merge folds a into the aggregates of g; it expects the individuals of a not
+// to have been accounted for since the last Reset.
+func (g *Genomes) Merge(a Aggregate) {
+	if a.n == 0 {
+		return
+	}
+
+	fittest, worst := a.argMax, a.argMin
+	if g.direction == Minimize {
+		fittest, worst = worst, fittest
+	}
+
+	g.totalFitness += a.totalFitness
+	if g.worst < 0 || g.direction.Better(g.fitness[g.worst], g.fitness[worst]) {
+		g.worst = worst
+	}
+	if g.fittest < 0 || g.direction.Better(g.fitness[fittest], g.fitness[g.fittest]) {
+		g.fittest = fittest
+	}
+}
+
+// Fittest returns the index and fitness of the fittest individual, or -1 and
+// NaN if no fitness has been set since the last Reset.
 func (g Genomes) Fittest() (int, float64) {
+	if g.fittest < 0 {
+		return -1, math.NaN()
+	}
 	return g.fittest, g.fitness[g.fittest]
 }
+
+// Worst returns the index and fitness of the worst individual, or -1 and NaN
+// if no fitness has been set since the last Reset.
 func (g Genomes) Worst() (int, float64) {
+	if g.worst < 0 {
+		return -1, math.NaN()
+	}
 	return g.worst, g.fitness[g.worst]
 }
 
+// MakeFitnessPositive turns the fitness values into non-negative merits,
+// larger being better. When maximizing, the values are shifted so that the
+// worst one is 0, if it was negative. When minimizing, each value is replaced
+// by its distance from the worst one, and the direction becomes Maximize.
 func (g *Genomes) MakeFitnessPositive() {
 	worstFitness := g.fitness[g.worst]
+	if g.direction == Minimize {
+		for i := range g.size {
+			g.fitness[i] = worstFitness - g.fitness[i]
+		}
+		g.totalFitness = worstFitness*float64(g.size) - g.totalFitness
+		g.direction = Maximize
+		return
+	}
+
 	if worstFitness >= 0 {
 		return
 	}
@@ -86,6 +250,73 @@ func (g *Genomes) MakeFitnessPositive() {
 	g.totalFitness -= worstFitness * float64(g.size)
 }
 
+// Merit returns the fitness of individual i as MakeFitnessPositive would
+// turn it, without changing it.
+func (g Genomes) Merit(i int) float64 {
+	worstFitness := g.fitness[g.worst]
+	switch {
+	case g.direction == Minimize:
+		return worstFitness - g.fitness[i]
+	case worstFitness < 0:
+		return g.fitness[i] - worstFitness
+	}
+	return g.fitness[i]
+}
+
+// TotalMerit returns the sum of the merits of all individuals.
+func (g Genomes) TotalMerit() float64 {
+	worstFitness := g.fitness[g.worst]
+	switch {
+	case g.direction == Minimize:
+		return worstFitness*float64(g.size) - g.totalFitness
+	case worstFitness < 0:
+		return g.totalFitness - worstFitness*float64(g.size)
+	}
+	return g.totalFitness
+}
+
+// Detach returns a copy of g sharing the same genotypes, but whose fitness
+// values are copied into buf and can be changed without affecting g.
+func (g Genomes) Detach(buf []float64) Genomes {
+	fitness := buf[:g.size]
+	copy(fitness, g.fitness)
+	g.fitness = fitness
+	return g
+}
+
+// Rescale replaces the fitness of each individual with f(i, fitness), and
+// recomputes the aggregates. Rescaled values are always to be maximized.
+func (g *Genomes) Rescale(f func(i int, fitness float64) float64) {
+	var a Aggregate
+	for i, fitness := range g.fitness {
+		fitness = f(i, fitness)
+		g.fitness[i] = fitness
+		a.Add(i, fitness)
+	}
+
+	g.direction = Maximize
+	g.totalFitness = 0
+	g.fittest = -1
+	g.worst = -1
+	g.isSorted = false
+	g.Merge(a)
+}
+
+// SortedIndices fills buf with the indices of the individuals, fittest first,
+// without moving them.
+func (g Genomes) SortedIndices(buf []int) []int {
+	buf = buf[:g.size]
+	for i := range buf {
+		buf[i] = i
+	}
+	slices.SortStableFunc(buf, func(a, b int) int {
+		return g.direction.Compare(g.fitness[b], g.fitness[a])
+	})
+	return buf
+}
+
+// SortByFitnessDesc sorts the individuals from the fittest to the worst: by
+// descending fitness when maximizing, by ascending fitness when minimizing.
 func (g *Genomes) SortByFitnessDesc() {
 	if g.isSorted {
 		return
@@ -95,12 +326,43 @@ func (g *Genomes) SortByFitnessDesc() {
 	g.isSorted = true
 }
 
+// Diversity measures how much the genotypes differ from each other, bit by
+// bit: it averages 4p(1-p) over all bit positions, p being the share of
+// individuals having that bit set. It ranges from 0, when all genotypes are
+// the same, to 1, when each bit is evenly split among the population.
+func (g Genomes) Diversity() float64 {
+	if g.size == 0 || g.chromosomeLen == 0 {
+		return 0
+	}
+
+	ones := make([]int, g.chromosomeLen*8)
+	for i := range g.size {
+		for j, b := range g.Genotype(i) {
+			for k := range 8 {
+				ones[j*8+k] += int(b>>k) & 1
+			}
+		}
+	}
+
+	var diversity float64
+	n := float64(g.size)
+	for _, o := range ones {
+		p := float64(o) / n
+		diversity += 4 * p * (1 - p)
+	}
+	return diversity / float64(len(ones))
+}
+
 type Stats struct {
+	Direction Direction
+
 	Fittest int
 	Worst   int
 
-	MinFitness float64
-	MaxFitness float64
+	BestFitness  float64
+	WorstFitness float64
+	MinFitness   float64
+	MaxFitness   float64
 
 	NValues      float64
 	TotalFitness float64
@@ -110,11 +372,20 @@ type Stats struct {
 }
 
 func (p Genomes) Stats() Stats {
+	best, worst := p.fitness[p.fittest], p.fitness[p.worst]
+	minFitness, maxFitness := worst, best
+	if p.direction == Minimize {
+		minFitness, maxFitness = best, worst
+	}
+
 	return Stats{
+		Direction:     p.direction,
 		Fittest:       p.fittest,
 		Worst:         p.worst,
-		MinFitness:    p.fitness[p.worst],
-		MaxFitness:    p.fitness[p.fittest],
+		BestFitness:   best,
+		WorstFitness:  worst,
+		MinFitness:    minFitness,
+		MaxFitness:    maxFitness,
 		NValues:       float64(p.size),
 		TotalFitness:  p.totalFitness,
 		Mean:          p.totalFitness / float64(p.size),
@@ -165,10 +436,3 @@ func (p Population[P]) Encode(i int, phenotype P) {
 func (p Population[P]) Decode(i int, phenotype *P) {
 	p.schema.Decode(phenotype, p.Genotype(i))
 }
-
-func (p *Population[P]) Reset() {
-	p.totalFitness = 0
-	p.fittest = -1
-	p.worst = -1
-	p.isSorted = false
-}
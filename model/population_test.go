@@ -0,0 +1,126 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/mbolis/genetta/genotype"
+	"github.com/mbolis/genetta/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func newPopulation(direction model.Direction, fitness ...float64) model.Population[[]byte] {
+	p := model.New(genotype.Binary[byte](8, 1), len(fitness))
+	p.SetDirection(direction)
+	p.Reset()
+	for i, f := range fitness {
+		p.Encode(i, []byte{byte(i)})
+		p.SetFitness(i, f)
+	}
+	return p
+}
+
+func TestDirection(t *testing.T) {
+	t.Run("should track the fittest when maximizing", func(t *testing.T) {
+		p := newPopulation(model.Maximize, 3, -1, 7, 2)
+
+		stats := p.Stats()
+		assert.Equal(t, 2, stats.Fittest)
+		assert.Equal(t, 1, stats.Worst)
+		assert.Equal(t, 7.0, stats.BestFitness)
+		assert.Equal(t, -1.0, stats.MinFitness)
+	})
+
+	t.Run("should track the fittest when minimizing", func(t *testing.T) {
+		p := newPopulation(model.Minimize, 3, -1, 7, 2)
+
+		stats := p.Stats()
+		assert.Equal(t, 1, stats.Fittest)
+		assert.Equal(t, 2, stats.Worst)
+		assert.Equal(t, -1.0, stats.BestFitness)
+		assert.Equal(t, -1.0, stats.MinFitness)
+		assert.Equal(t, 7.0, stats.MaxFitness)
+	})
+
+	t.Run("should merge aggregates according to direction", func(t *testing.T) {
+		for _, d := range []model.Direction{model.Maximize, model.Minimize} {
+			expected := newPopulation(d, 3, -1, 7, 2, 5)
+
+			p := newPopulation(d, 0, 0, 0, 0, 0)
+			p.Reset()
+			var a1, a2 model.Aggregate
+			for i, f := range []float64{3, -1, 7, 2, 5} {
+				p.StoreFitness(i, f)
+				if i%2 == 0 {
+					a1.Add(i, f)
+				} else {
+					a2.Add(i, f)
+				}
+			}
+			p.Merge(a1)
+			p.Merge(a2)
+
+			assert.Equal(t, expected.Stats(), p.Stats())
+		}
+	})
+
+	t.Run("should track the fittest and the worst when they are overwritten", func(t *testing.T) {
+		for _, d := range []model.Direction{model.Maximize, model.Minimize} {
+			p := newPopulation(d, 3, -1, 7, 2)
+			p.SetFitness(2, 2.5)
+			p.SetFitness(1, 2.5)
+
+			expected := newPopulation(d, 3, 2.5, 2.5, 2)
+			assert.Equal(t, expected.Stats(), p.Stats())
+		}
+	})
+
+	t.Run("should sort fittest first", func(t *testing.T) {
+		p := newPopulation(model.Minimize, 3, -1, 7, 2)
+		assert.Equal(t, []int{1, 3, 0, 2}, p.SortedIndices(make([]int, 4)))
+
+		p.SortByFitnessDesc()
+		for i, g := range []byte{1, 3, 0, 2} {
+			assert.Equal(t, []byte{g}, p.Genotype(i))
+		}
+
+		fittest, best := p.Fittest()
+		assert.Equal(t, 0, fittest)
+		assert.Equal(t, -1.0, best)
+		worst, worstFitness := p.Worst()
+		assert.Equal(t, 3, worst)
+		assert.Equal(t, 7.0, worstFitness)
+		assert.Equal(t, 8.0, p.Merit(0))
+		assert.Equal(t, 0.0, p.Merit(3))
+	})
+}
+
+func TestMerit(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		direction model.Direction
+		fitness   []float64
+		merits    []float64
+	}{
+		{"positive maximized", model.Maximize, []float64{3, 1, 7}, []float64{3, 1, 7}},
+		{"negative maximized", model.Maximize, []float64{3, -1, 7}, []float64{4, 0, 8}},
+		{"minimized", model.Minimize, []float64{3, -1, 7}, []float64{4, 8, 0}},
+	} {
+		t.Run("should compute merits of "+tc.name, func(t *testing.T) {
+			p := newPopulation(tc.direction, tc.fitness...)
+
+			var total float64
+			for i, m := range tc.merits {
+				assert.Equal(t, m, p.Merit(i))
+				total += m
+			}
+			assert.Equal(t, total, p.TotalMerit())
+
+			p.MakeFitnessPositive()
+			assert.Equal(t, model.Maximize, p.Direction())
+			for i, m := range tc.merits {
+				assert.Equal(t, m, p.Fitness(i))
+			}
+			assert.Equal(t, total, p.Stats().TotalFitness)
+		})
+	}
+}
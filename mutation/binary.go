@@ -1,14 +1,17 @@
 package mutation
 
 import (
+	"errors"
 	"math/rand/v2"
 	"reflect"
+
+	"github.com/mbolis/genetta/layout"
 )
 
 type binary struct{}
 
 func (binary) IsCompatible(chromosomeType reflect.Kind, flags uint) bool {
-	return chromosomeType == reflect.Int // TODO no permutation should be allowed
+	return chromosomeType == reflect.Int && flags&layout.FlagPermutation == 0
 }
 
 type bitString struct {
@@ -38,3 +41,51 @@ func (b bitString) Mutate(genome []byte) error {
 	}
 	return nil
 }
+
+var errResetUnbound = errors.New("random reset mutation is not bound to any gene layout")
+
+type randomReset struct {
+	binary
+
+	genes []layout.Gene
+	rate  float64
+}
+
+// RandomReset replaces each gene, with probability rate, with a random value
+// drawn uniformly among the values it can take: within its range if bounded,
+// which includes categorical genes, and among all the values that fit its bits
+// otherwise.
+func RandomReset(rate float64) Operator {
+	checkRate(rate)
+	return randomReset{rate: rate}
+}
+
+func (r randomReset) Bind(l layout.Chromosome) Operator {
+	return randomReset{genes: l.Genes, rate: r.rate}
+}
+
+func (r randomReset) Mutate(genotype []byte) error {
+	if r.genes == nil {
+		return errResetUnbound
+	}
+
+	for _, g := range r.genes {
+		if rand.Float64() >= r.rate {
+			continue
+		}
+
+		// bounded genes hold their distance from Min
+		span := g.Max - g.Min
+		if span >= 1<<63 {
+			g.SetUint(genotype, rand.Uint64())
+			continue
+		}
+
+		v := rand.Uint64N(uint64(span) + 1)
+		if g.Gray {
+			v ^= v >> 1
+		}
+		g.SetUint(genotype, v)
+	}
+	return nil
+}
@@ -2,9 +2,12 @@ package mutation_test
 
 import (
 	"fmt"
+	"math"
 	"math/bits"
+	"reflect"
 	"testing"
 
+	"github.com/mbolis/genetta/layout"
 	"github.com/mbolis/genetta/mutation"
 	"github.com/stretchr/testify/assert"
 )
@@ -32,3 +35,39 @@ func TestBitString(t *testing.T) {
 		})
 	}
 }
+
+func TestRandomReset(t *testing.T) {
+	// a gene bounded within [10, 12], Gray coded, and an unbounded one, both 2
+	// bits wide
+	l := layout.Chromosome{
+		Genes: []layout.Gene{
+			{Offset: 0, Width: 2, Kind: reflect.Uint64, Min: 10, Max: 12, Gray: true},
+			{Offset: 2, Width: 2, Kind: reflect.Uint64, Min: math.Inf(-1), Max: math.Inf(1)},
+		},
+		Bytes: 1,
+	}
+	op := mutation.Bind(mutation.RandomReset(0.5), l)
+
+	var bounded, unbounded [4]int
+	for range repeats {
+		genome := []byte{0}
+		assert.NoError(t, op.Mutate(genome))
+
+		bounded[l.Genes[0].Uint(genome)]++
+		unbounded[l.Genes[1].Uint(genome)]++
+	}
+
+	// 0, 1 and 2 are Gray coded as 0, 1 and 3
+	assert.Zero(t, bounded[2], "should stay within bounds")
+	assert.InEpsilon(t, repeats/2+repeats/6, bounded[0], 0.05)
+	assert.InEpsilon(t, repeats/6, bounded[1], 0.1)
+	assert.InEpsilon(t, repeats/6, bounded[3], 0.1)
+	assert.InEpsilon(t, repeats/2+repeats/8, unbounded[0], 0.05)
+	for _, n := range unbounded[1:] {
+		assert.InEpsilon(t, repeats/8, n, 0.1)
+	}
+
+	assert.Error(t, mutation.RandomReset(0.5).Mutate([]byte{0}), "should fail when unbound")
+	assert.True(t, op.IsCompatible(reflect.Int, 0))
+	assert.False(t, op.IsCompatible(reflect.Float64, 0))
+}
@@ -1,8 +1,69 @@
 package mutation
 
-import "reflect"
+import (
+	"math/rand/v2"
+	"reflect"
+
+	"github.com/mbolis/genetta/layout"
+)
 
 type Operator interface {
 	Mutate(genotype []byte) error
 	IsCompatible(chromosomeType reflect.Kind, flags uint) bool
 }
+
+// Cloner is implemented by operators holding scratch state, which therefore
+// cannot be shared among goroutines.
+type Cloner interface {
+	Clone() Operator
+}
+
+// Clone returns a copy of op that can be used concurrently with op itself.
+func Clone(op Operator) Operator {
+	if c, ok := op.(Cloner); ok {
+		return c.Clone()
+	}
+	return op
+}
+
+// Binder is implemented by operators that need to know the layout of the
+// genes within the chromosome they are applied to.
+type Binder interface {
+	Bind(layout.Chromosome) Operator
+}
+
+// Bind returns a copy of op aware of the layout of the chromosome, or op
+// itself if it does not care.
+func Bind(op Operator, l layout.Chromosome) Operator {
+	if b, ok := op.(Binder); ok {
+		return b.Bind(l)
+	}
+	return op
+}
+
+type probability struct {
+	probability float64
+	Operator
+}
+
+// Probability applies s to each chromosome with probability p, leaving it
+// untouched otherwise.
+func Probability(p float64, s Operator) Operator {
+	return probability{p, s}
+}
+
+func (p probability) Mutate(genotype []byte) error {
+	if rand.Float64() >= p.probability {
+		return nil
+	}
+
+	return p.Operator.Mutate(genotype)
+}
+
+func (p probability) Clone() Operator {
+	return probability{p.probability, Clone(p.Operator)}
+}
+
+func (p probability) Bind(l layout.Chromosome) Operator {
+	return probability{p.probability, Bind(p.Operator, l)}
+}
@@ -0,0 +1,131 @@
+package mutation
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"reflect"
+	"slices"
+
+	"github.com/mbolis/genetta/layout"
+)
+
+var errPermutationUnbound = errors.New("permutation mutation is not bound to any gene layout")
+
+// permutation operators decode the chromosome into a permutation of 0..n-1,
+// and let mutate rearrange it.
+type permutation struct {
+	mutate func(perm []int)
+
+	genes []layout.Gene
+	perm  []int
+	used  []bool
+}
+
+func newPermutation(mutate func(perm []int)) Operator {
+	return &permutation{mutate: mutate}
+}
+
+func (*permutation) IsCompatible(chromosomeType reflect.Kind, flags uint) bool {
+	return chromosomeType == reflect.Int && flags&layout.FlagPermutation != 0
+}
+
+func (p *permutation) Bind(l layout.Chromosome) Operator {
+	return &permutation{
+		mutate: p.mutate,
+		genes:  l.Genes,
+		perm:   make([]int, len(l.Genes)),
+		used:   make([]bool, len(l.Genes)),
+	}
+}
+
+func (p *permutation) Clone() Operator {
+	if p.genes == nil {
+		return &permutation{mutate: p.mutate}
+	}
+	return p.Bind(layout.Chromosome{Genes: p.genes})
+}
+
+func (p *permutation) Mutate(genotype []byte) error {
+	if p.genes == nil {
+		return errPermutationUnbound
+	}
+
+	clear(p.used)
+	for i, g := range p.genes {
+		v := g.Uint(genotype)
+		if v >= uint64(len(p.genes)) || p.used[v] {
+			return fmt.Errorf("chromosome is not a permutation: gene %d is %d", i, v)
+		}
+		p.used[v] = true
+		p.perm[i] = int(v)
+	}
+
+	if len(p.perm) < 2 {
+		return nil
+	}
+	p.mutate(p.perm)
+
+	for i, g := range p.genes {
+		g.SetUint(genotype, uint64(p.perm[i]))
+	}
+	return nil
+}
+
+// twoPositions picks two distinct positions at random, i < j.
+func twoPositions(n int) (i, j int) {
+	i = rand.IntN(n)
+	j = rand.IntN(n - 1)
+	if j >= i {
+		j++
+	} else {
+		i, j = j, i
+	}
+	return
+}
+
+// Swap exchanges two genes picked at random.
+func Swap() Operator {
+	return newPermutation(func(perm []int) {
+		i, j := twoPositions(len(perm))
+		perm[i], perm[j] = perm[j], perm[i]
+	})
+}
+
+// Insert moves a gene picked at random to another random position, shifting
+// the genes in between.
+func Insert() Operator {
+	return newPermutation(func(perm []int) {
+		from, to := twoPositions(len(perm))
+		if rand.IntN(2) == 0 {
+			from, to = to, from
+		}
+
+		v := perm[from]
+		if from < to {
+			copy(perm[from:to], perm[from+1:to+1])
+		} else {
+			copy(perm[to+1:from+1], perm[to:from])
+		}
+		perm[to] = v
+	})
+}
+
+// Inversion reverses the order of the genes within a random segment.
+func Inversion() Operator {
+	return newPermutation(func(perm []int) {
+		i, j := twoPositions(len(perm))
+		slices.Reverse(perm[i : j+1])
+	})
+}
+
+// Scramble shuffles the genes within a random segment.
+func Scramble() Operator {
+	return newPermutation(func(perm []int) {
+		i, j := twoPositions(len(perm))
+		segment := perm[i : j+1]
+		rand.Shuffle(len(segment), func(a, b int) {
+			segment[a], segment[b] = segment[b], segment[a]
+		})
+	})
+}
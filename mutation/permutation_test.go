@@ -0,0 +1,152 @@
+package mutation_test
+
+import (
+	"math/rand/v2"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/mbolis/genetta/layout"
+	"github.com/mbolis/genetta/mutation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// permutationLayout describes 10 genes of 4 bits
+var permutationLayout = func() layout.Chromosome {
+	c := layout.Chromosome{Bytes: 5, Flags: layout.FlagPermutation}
+	for i := range 10 {
+		c.Genes = append(c.Genes, layout.Gene{Offset: i * 4, Width: 4, Kind: reflect.Int})
+	}
+	return c
+}()
+
+func mutatePermutation(t *testing.T, op mutation.Operator, perm []int) []int {
+	t.Helper()
+
+	data := make([]byte, permutationLayout.Bytes)
+	for i, g := range permutationLayout.Genes {
+		g.SetUint(data, uint64(perm[i]))
+	}
+	require.NoError(t, op.Mutate(data))
+
+	mutated := make([]int, len(perm))
+	for i, g := range permutationLayout.Genes {
+		mutated[i] = int(g.Uint(data))
+	}
+	require.ElementsMatch(t, perm, mutated, "should be a permutation")
+	return mutated
+}
+
+// changed returns the first and last positions where a and b differ
+func changed(a, b []int) (first, last int) {
+	first, last = -1, -1
+	for i := range a {
+		if a[i] != b[i] {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	return
+}
+
+func TestPermutationCompatibility(t *testing.T) {
+	for _, op := range []mutation.Operator{mutation.Swap(), mutation.Insert(), mutation.Inversion(), mutation.Scramble()} {
+		assert.True(t, op.IsCompatible(reflect.Int, layout.FlagPermutation))
+		assert.False(t, op.IsCompatible(reflect.Int, 0))
+		assert.Error(t, op.Mutate(make([]byte, 5)), "should fail when unbound")
+		assert.Error(t, mutation.Bind(op, permutationLayout).Mutate(make([]byte, 5)), "should reject non permutations")
+	}
+	assert.False(t, mutation.BitString(1).IsCompatible(reflect.Int, layout.FlagPermutation))
+}
+
+func TestSwap(t *testing.T) {
+	op := mutation.Bind(mutation.Swap(), permutationLayout)
+
+	for range repeats {
+		perm := rand.Perm(10)
+		mutated := mutatePermutation(t, op, perm)
+
+		first, last := changed(perm, mutated)
+		require.GreaterOrEqual(t, first, 0)
+		assert.Equal(t, perm[first], mutated[last])
+		assert.Equal(t, perm[last], mutated[first])
+		assert.Equal(t, perm[first+1:last], mutated[first+1:last])
+	}
+}
+
+func TestInsert(t *testing.T) {
+	op := mutation.Bind(mutation.Insert(), permutationLayout)
+
+	var forward, backward int
+	for range repeats {
+		perm := rand.Perm(10)
+		mutated := mutatePermutation(t, op, perm)
+
+		first, last := changed(perm, mutated)
+		require.GreaterOrEqual(t, first, 0)
+		switch {
+		case last == first+1:
+			// moving a gene next to its neighbor is just a swap
+			assert.Equal(t, perm[first], mutated[last])
+			assert.Equal(t, perm[last], mutated[first])
+		case mutated[last] == perm[first]:
+			assert.Equal(t, perm[first+1:last+1], mutated[first:last])
+			forward++
+		case mutated[first] == perm[last]:
+			assert.Equal(t, perm[first:last], mutated[first+1:last+1])
+			backward++
+		default:
+			t.Fatalf("%v is not an insertion into %v", mutated, perm)
+		}
+	}
+	assert.InEpsilon(t, forward, backward, 0.1)
+}
+
+func TestInversion(t *testing.T) {
+	op := mutation.Bind(mutation.Inversion(), permutationLayout)
+
+	for range repeats {
+		perm := rand.Perm(10)
+		mutated := mutatePermutation(t, op, perm)
+
+		first, last := changed(perm, mutated)
+		require.GreaterOrEqual(t, first, 0)
+		segment := slices.Clone(perm[first : last+1])
+		slices.Reverse(segment)
+		assert.Equal(t, segment, mutated[first:last+1])
+	}
+}
+
+func TestScramble(t *testing.T) {
+	op := mutation.Bind(mutation.Scramble(), permutationLayout)
+
+	var unchanged int
+	for range repeats {
+		perm := rand.Perm(10)
+		mutated := mutatePermutation(t, op, perm)
+
+		first, last := changed(perm, mutated)
+		if first < 0 {
+			unchanged++
+			continue
+		}
+		assert.ElementsMatch(t, perm[first:last+1], mutated[first:last+1])
+	}
+	assert.Less(t, unchanged, repeats/2)
+}
+
+func TestProbability(t *testing.T) {
+	op := mutation.Bind(mutation.Probability(0.25, mutation.Swap()), permutationLayout)
+
+	var mutated int
+	for range repeats {
+		perm := rand.Perm(10)
+		if !slices.Equal(perm, mutatePermutation(t, op, perm)) {
+			mutated++
+		}
+	}
+	assert.InEpsilon(t, repeats/4, mutated, 0.1)
+}
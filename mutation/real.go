@@ -0,0 +1,183 @@
+package mutation
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"reflect"
+
+	"github.com/mbolis/genetta/layout"
+)
+
+var errUnbound = errors.New("real-valued mutation is not bound to any gene layout")
+
+// realValued operators decode the genes as floating point values, so they
+// need to be bound to the layout of the chromosome. Each floating point gene
+// is mutated independently with probability rate, while any other gene is left
+// untouched.
+type realValued struct {
+	genes []layout.Gene
+	rate  float64
+}
+
+func (realValued) IsCompatible(chromosomeType reflect.Kind, flags uint) bool {
+	return chromosomeType == reflect.Float32 || chromosomeType == reflect.Float64
+}
+
+// perturb replaces the genes picked for mutation with f applied to their
+// value, clamped to the bounds of the gene.
+func (r realValued) perturb(genotype []byte, f func(g layout.Gene, x float64) float64) error {
+	if r.genes == nil {
+		return errUnbound
+	}
+
+	for _, g := range r.genes {
+		if rand.Float64() < r.rate {
+			g.SetFloat(genotype, g.Clamp(f(g, g.Float(genotype))))
+		}
+	}
+	return nil
+}
+
+// bounded fails unless all genes have finite bounds.
+func (r realValued) bounded(name string) error {
+	for i, g := range r.genes {
+		if math.IsInf(g.Min, 0) || math.IsInf(g.Max, 0) {
+			return fmt.Errorf("%s mutation needs bounded genes: gene %d is unbounded", name, i)
+		}
+	}
+	return nil
+}
+
+func checkRate(rate float64) {
+	if rate < 0 || rate > 1 {
+		panic(fmt.Sprintf("invalid mutation rate: %f", rate)) // TODO
+	}
+}
+
+type gaussian struct {
+	realValued
+
+	sigma float64
+}
+
+// Gaussian adds to each gene, with probability rate, a normally distributed
+// value with mean 0 and standard deviation sigma.
+func Gaussian(sigma, rate float64) Operator {
+	if sigma <= 0 {
+		panic(fmt.Sprintf("invalid gaussian mutation deviation: %f", sigma)) // TODO
+	}
+	checkRate(rate)
+	return gaussian{realValued{rate: rate}, sigma}
+}
+
+func (m gaussian) Bind(l layout.Chromosome) Operator {
+	return gaussian{realValued{l.FloatGenes(), m.rate}, m.sigma}
+}
+
+func (m gaussian) Mutate(genotype []byte) error {
+	return m.perturb(genotype, func(_ layout.Gene, x float64) float64 {
+		return x + m.sigma*rand.NormFloat64()
+	})
+}
+
+type cauchy struct {
+	realValued
+
+	scale float64
+}
+
+// Cauchy adds to each gene, with probability rate, a value drawn from a
+// Cauchy distribution centered on 0. Its heavy tails make long jumps far more
+// likely than Gaussian does with the same scale.
+func Cauchy(scale, rate float64) Operator {
+	if scale <= 0 {
+		panic(fmt.Sprintf("invalid cauchy mutation scale: %f", scale)) // TODO
+	}
+	checkRate(rate)
+	return cauchy{realValued{rate: rate}, scale}
+}
+
+func (m cauchy) Bind(l layout.Chromosome) Operator {
+	return cauchy{realValued{l.FloatGenes(), m.rate}, m.scale}
+}
+
+func (m cauchy) Mutate(genotype []byte) error {
+	return m.perturb(genotype, func(_ layout.Gene, x float64) float64 {
+		return x + m.scale*math.Tan(math.Pi*(rand.Float64()-0.5))
+	})
+}
+
+type polynomial struct {
+	realValued
+
+	eta float64
+}
+
+// Polynomial is the polynomial mutation of NSGA-II: it moves each gene, with
+// probability rate, by a random fraction of the range of the gene, whose
+// distribution shrinks towards 0 as the distribution index eta grows and as
+// the gene gets closer to its bounds. Genes must be bounded.
+func Polynomial(eta, rate float64) Operator {
+	if eta < 0 {
+		panic(fmt.Sprintf("invalid polynomial mutation distribution index: %f", eta)) // TODO
+	}
+	checkRate(rate)
+	return polynomial{realValued{rate: rate}, eta}
+}
+
+func (m polynomial) Bind(l layout.Chromosome) Operator {
+	return polynomial{realValued{l.FloatGenes(), m.rate}, m.eta}
+}
+
+func (m polynomial) Mutate(genotype []byte) error {
+	if err := m.bounded("polynomial"); err != nil {
+		return err
+	}
+
+	return m.perturb(genotype, func(g layout.Gene, x float64) float64 {
+		span := g.Max - g.Min
+		if span == 0 {
+			return x
+		}
+
+		u := rand.Float64()
+		var delta float64
+		if u < 0.5 {
+			xy := 1 - (x-g.Min)/span
+			v := 2*u + (1-2*u)*math.Pow(xy, m.eta+1)
+			delta = math.Pow(v, 1/(m.eta+1)) - 1
+		} else {
+			xy := 1 - (g.Max-x)/span
+			v := 2*(1-u) + 2*(u-0.5)*math.Pow(xy, m.eta+1)
+			delta = 1 - math.Pow(v, 1/(m.eta+1))
+		}
+		return x + delta*span
+	})
+}
+
+type uniformReset struct {
+	realValued
+}
+
+// UniformReset replaces each gene, with probability rate, with a value drawn
+// uniformly within its bounds. Genes must be bounded.
+func UniformReset(rate float64) Operator {
+	checkRate(rate)
+	return uniformReset{realValued{rate: rate}}
+}
+
+func (m uniformReset) Bind(l layout.Chromosome) Operator {
+	return uniformReset{realValued{l.FloatGenes(), m.rate}}
+}
+
+func (m uniformReset) Mutate(genotype []byte) error {
+	if err := m.bounded("uniform reset"); err != nil {
+		return err
+	}
+
+	return m.perturb(genotype, func(g layout.Gene, _ float64) float64 {
+		return g.Min + rand.Float64()*(g.Max-g.Min)
+	})
+}
@@ -0,0 +1,156 @@
+package mutation_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/mbolis/genetta/layout"
+	"github.com/mbolis/genetta/mutation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// realLayout describes a float64 gene bounded within [0, 10] followed by a
+// float32 gene bounded within [-1, 1]
+var realLayout = layout.Chromosome{
+	Genes: []layout.Gene{
+		{Offset: 0, Width: 64, Kind: reflect.Float64, Min: 0, Max: 10},
+		{Offset: 64, Width: 32, Kind: reflect.Float32, Min: -1, Max: 1},
+	},
+	Bytes: 12,
+}
+
+// unboundedLayout describes a single unbounded float64 gene
+var unboundedLayout = layout.Chromosome{
+	Genes: []layout.Gene{
+		{Offset: 0, Width: 64, Kind: reflect.Float64, Min: math.Inf(-1), Max: math.Inf(1)},
+	},
+	Bytes: 8,
+}
+
+func mutateReal(t *testing.T, op mutation.Operator, x, y float64) (float64, float64) {
+	t.Helper()
+
+	data := make([]byte, realLayout.Bytes)
+	realLayout.Genes[0].SetFloat(data, x)
+	realLayout.Genes[1].SetFloat(data, y)
+	require.NoError(t, op.Mutate(data))
+
+	x, y = realLayout.Genes[0].Float(data), realLayout.Genes[1].Float(data)
+	require.True(t, x >= 0 && x <= 10, "%f should be within bounds", x)
+	require.True(t, y >= -1 && y <= 1, "%f should be within bounds", y)
+	return x, y
+}
+
+func TestRealCompatibility(t *testing.T) {
+	for _, op := range []mutation.Operator{
+		mutation.Gaussian(1, 0.5),
+		mutation.Cauchy(1, 0.5),
+		mutation.Polynomial(20, 0.5),
+		mutation.UniformReset(0.5),
+	} {
+		assert.True(t, op.IsCompatible(reflect.Float32, 0))
+		assert.True(t, op.IsCompatible(reflect.Float64, 0))
+		assert.False(t, op.IsCompatible(reflect.Int, 0))
+		assert.Error(t, op.Mutate(make([]byte, 12)), "should fail when unbound")
+	}
+	assert.False(t, mutation.BitString(1).IsCompatible(reflect.Float64, 0))
+}
+
+func TestGaussian(t *testing.T) {
+	op := mutation.Bind(mutation.Gaussian(0.1, 0.5), realLayout)
+
+	var mutated int
+	var sum, sumSq float64
+	for range repeats {
+		x, _ := mutateReal(t, op, 5, 0)
+		if x != 5 {
+			mutated++
+			sum += x - 5
+			sumSq += (x - 5) * (x - 5)
+		}
+	}
+
+	assert.InEpsilon(t, repeats/2, mutated, 0.05)
+	mean := sum / float64(mutated)
+	assert.InDelta(t, 0, mean, 0.01)
+	assert.InEpsilon(t, 0.1, math.Sqrt(sumSq/float64(mutated)-mean*mean), 0.05)
+}
+
+func TestCauchy(t *testing.T) {
+	op := mutation.Bind(mutation.Cauchy(0.1, 1), realLayout)
+
+	// half of a Cauchy distribution lies within one scale from its center
+	var within, above int
+	for range repeats {
+		x, _ := mutateReal(t, op, 5, 0)
+		if math.Abs(x-5) < 0.1 {
+			within++
+		}
+		if x > 5 {
+			above++
+		}
+	}
+	assert.InEpsilon(t, repeats/2, within, 0.05)
+	assert.InEpsilon(t, repeats/2, above, 0.05)
+}
+
+func TestPolynomial(t *testing.T) {
+	op := mutation.Bind(mutation.Polynomial(20, 1), realLayout)
+
+	var sum float64
+	for range repeats {
+		x, y := mutateReal(t, op, 5, -1)
+		assert.GreaterOrEqual(t, y, -1.0)
+		sum += x - 5
+	}
+	assert.InDelta(t, 0, sum/repeats, 0.03, "mutations should be symmetric away from bounds")
+
+	assert.Error(t, mutation.Bind(mutation.Polynomial(20, 1), unboundedLayout).Mutate(make([]byte, 8)))
+}
+
+func TestUniformReset(t *testing.T) {
+	op := mutation.Bind(mutation.UniformReset(1), realLayout)
+
+	var sumX, sumY float64
+	for range repeats {
+		x, y := mutateReal(t, op, 0, 0)
+		sumX += x
+		sumY += y
+	}
+	assert.InDelta(t, 5, sumX/repeats, 0.15)
+	assert.InDelta(t, 0, sumY/repeats, 0.03)
+
+	assert.Error(t, mutation.Bind(mutation.UniformReset(1), unboundedLayout).Mutate(make([]byte, 8)))
+}
+
+func TestRealMixedGenes(t *testing.T) {
+	// a float64 gene followed by an int32 gene
+	l := layout.Chromosome{
+		Genes: []layout.Gene{
+			{Offset: 0, Width: 64, Kind: reflect.Float64, Min: 0, Max: 10},
+			{Offset: 64, Width: 32, Kind: reflect.Int32, Min: math.Inf(-1), Max: math.Inf(1)},
+		},
+		Bytes: 12,
+	}
+
+	for _, op := range []mutation.Operator{
+		mutation.Gaussian(1, 1),
+		mutation.Cauchy(1, 1),
+		mutation.Polynomial(20, 1),
+		mutation.UniformReset(1),
+	} {
+		op = mutation.Bind(op, l)
+		for range 100 {
+			data := make([]byte, l.Bytes)
+			l.Genes[0].SetFloat(data, 5)
+			l.Genes[1].SetUint(data, 42)
+			require.NoError(t, op.Mutate(data))
+
+			assert.Equal(t, uint64(42), l.Genes[1].Uint(data), "should leave integer genes untouched")
+			x := l.Genes[0].Float(data)
+			assert.True(t, x >= 0 && x <= 10, "%f out of bounds", x)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package genetta
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mbolis/genetta/model"
+	"github.com/mbolis/genetta/termination"
+)
+
+// Event describes a run right after a generation has been evaluated.
+// EvaluationsAvoided counts the fitness evaluations spared by the fitness
+// cache, if any.
+type Event[P any] struct {
+	Generation         int
+	Evaluations        int
+	EvaluationsAvoided int
+	Elapsed            time.Duration
+
+	Stats model.Stats
+	Best  Result[P]
+}
+
+// Observer is notified after the evaluation of each generation.
+type Observer[P any] interface {
+	OnGeneration(Event[P])
+}
+
+// NewBestObserver can be implemented by an Observer to be notified also when
+// the best fitness found so far improves.
+type NewBestObserver[P any] interface {
+	OnNewBest(Event[P])
+}
+
+// TerminationObserver can be implemented by an Observer to be notified also
+// when a termination criterion stops the run.
+type TerminationObserver[P any] interface {
+	OnTermination(Event[P], termination.Criterion)
+}
+
+type ObserverFunc[P any] func(Event[P])
+
+func (f ObserverFunc[P]) OnGeneration(e Event[P]) {
+	f(e)
+}
+
+// WithObserver registers an observer; observers are called synchronously, in
+// the order they were registered, from the goroutine running the solver.
+func WithObserver[P any](obs Observer[P]) func(*options) error {
+	return func(o *options) error {
+		if obs == nil {
+			return fmt.Errorf("observer must not be nil")
+		}
+
+		o.observers = append(o.observers, obs)
+		return nil
+	}
+}
+
+func observersFor[P any](observers []any) ([]Observer[P], error) {
+	out := make([]Observer[P], len(observers))
+	for i, o := range observers {
+		obs, ok := o.(Observer[P])
+		if !ok {
+			var p P
+			return nil, fmt.Errorf("observer %T cannot observe phenotype %T", o, p)
+		}
+		out[i] = obs
+	}
+	return out, nil
+}
+
+func (ga *gaSolver[P]) notify(e Event[P], newBest bool) {
+	for _, o := range ga.observers {
+		o.OnGeneration(e)
+	}
+
+	if newBest {
+		for _, o := range ga.observers {
+			if o, ok := o.(NewBestObserver[P]); ok {
+				o.OnNewBest(e)
+			}
+		}
+	}
+
+	if stoppedBy := e.Best.StoppedBy(); stoppedBy != nil {
+		for _, o := range ga.observers {
+			if o, ok := o.(TerminationObserver[P]); ok {
+				o.OnTermination(e, stoppedBy)
+			}
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package replacement
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/mbolis/genetta/model"
+)
+
+// Strategy builds the next population out of the current one and of its
+// offspring, both already evaluated. Without a Strategy, the solver uses
+// generational replacement: the offspring simply take the place of their
+// parents.
+type Strategy interface {
+	// Offspring returns how many children to breed for a population of mu
+	// individuals.
+	Offspring(mu int) int
+	// Replace fills next with the survivors among parents and offspring,
+	// along with their fitness. The genotypes of next are distinct from those
+	// of parents and offspring.
+	Replace(parents, offspring model.Genomes, next *model.Genomes) error
+}
+
+// Validator is implemented by strategies that cannot work with any
+// population size.
+type Validator interface {
+	Validate(mu int) error
+}
+
+// Validate fails if s cannot replace a population of mu individuals.
+func Validate(s Strategy, mu int) error {
+	if v, ok := s.(Validator); ok {
+		return v.Validate(mu)
+	}
+	return nil
+}
+
+type muCommaLambda struct {
+	lambda  int
+	indices []int
+}
+
+// MuCommaLambda breeds lambda children, and keeps the fittest of them only:
+// parents never survive, so lambda must be at least as large as the
+// population.
+func MuCommaLambda(lambda int) Strategy {
+	if lambda <= 0 {
+		panic(fmt.Sprintf("invalid offspring size: %d", lambda)) // TODO
+	}
+	return &muCommaLambda{lambda: lambda}
+}
+
+func (m *muCommaLambda) Offspring(int) int {
+	return m.lambda
+}
+
+func (m *muCommaLambda) Validate(mu int) error {
+	if m.lambda < mu {
+		return fmt.Errorf("not enough offspring to replace %d parents: %d", mu, m.lambda)
+	}
+	return nil
+}
+
+func (m *muCommaLambda) Replace(parents, offspring model.Genomes, next *model.Genomes) error {
+	mu := next.NIndividuals()
+	if offspring.NIndividuals() < mu {
+		return fmt.Errorf("not enough offspring to replace %d parents: %d", mu, offspring.NIndividuals())
+	}
+
+	if cap(m.indices) < offspring.NIndividuals() {
+		m.indices = make([]int, offspring.NIndividuals())
+	}
+	indices := offspring.SortedIndices(m.indices)
+
+	next.Reset()
+	for k, i := range indices[:mu] {
+		copy(next.Genotype(k), offspring.Genotype(i))
+		next.SetFitness(k, offspring.Fitness(i))
+	}
+	return nil
+}
+
+type muPlusLambda struct {
+	lambda     int
+	candidates []candidate
+}
+
+type candidate struct {
+	genotype []byte
+	fitness  float64
+}
+
+// MuPlusLambda breeds lambda children, and keeps the fittest individuals
+// among parents and children together. Ties favor the parents.
+func MuPlusLambda(lambda int) Strategy {
+	if lambda <= 0 {
+		panic(fmt.Sprintf("invalid offspring size: %d", lambda)) // TODO
+	}
+	return &muPlusLambda{lambda: lambda}
+}
+
+func (m *muPlusLambda) Offspring(int) int {
+	return m.lambda
+}
+
+func (m *muPlusLambda) Replace(parents, offspring model.Genomes, next *model.Genomes) error {
+	mu := next.NIndividuals()
+	n := parents.NIndividuals() + offspring.NIndividuals()
+	if n < mu {
+		return fmt.Errorf("not enough individuals to fill a population of %d: %d", mu, n)
+	}
+
+	m.candidates = m.candidates[:0]
+	for _, g := range []model.Genomes{parents, offspring} {
+		for i := range g.NIndividuals() {
+			m.candidates = append(m.candidates, candidate{g.Genotype(i), g.Fitness(i)})
+		}
+	}
+
+	direction := next.Direction()
+	slices.SortStableFunc(m.candidates, func(a, b candidate) int {
+		return direction.Compare(b.fitness, a.fitness)
+	})
+
+	next.Reset()
+	for k, c := range m.candidates[:mu] {
+		copy(next.Genotype(k), c.genotype)
+		next.SetFitness(k, c.fitness)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package replacement_test
+
+import (
+	"testing"
+
+	"github.com/mbolis/genetta/genotype"
+	"github.com/mbolis/genetta/model"
+	"github.com/mbolis/genetta/replacement"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// genomes returns individuals whose only gene is their fitness
+func genomes(direction model.Direction, fitness ...byte) model.Genomes {
+	p := model.New(genotype.Binary[byte](8, 1), len(fitness))
+	p.SetDirection(direction)
+	p.Reset()
+	for i, f := range fitness {
+		p.Encode(i, []byte{f})
+		p.SetFitness(i, float64(f))
+	}
+	return p.Genomes
+}
+
+func genes(g model.Genomes) []byte {
+	out := make([]byte, g.NIndividuals())
+	for i := range out {
+		out[i] = g.Genotype(i)[0]
+		if g.Fitness(i) != float64(out[i]) {
+			panic("fitness does not match genotype")
+		}
+	}
+	return out
+}
+
+func TestMuCommaLambda(t *testing.T) {
+	s := replacement.MuCommaLambda(5)
+	assert.Equal(t, 5, s.Offspring(3))
+
+	t.Run("should keep the fittest offspring only", func(t *testing.T) {
+		parents := genomes(model.Maximize, 9, 9, 9)
+		next := genomes(model.Maximize, 0, 0, 0)
+		require.NoError(t, s.Replace(parents, genomes(model.Maximize, 4, 1, 5, 2, 3), &next))
+
+		assert.Equal(t, []byte{5, 4, 3}, genes(next))
+		assert.Equal(t, 12.0, next.Stats().TotalFitness)
+		fittest, _ := next.Fittest()
+		assert.Equal(t, 0, fittest)
+	})
+	t.Run("should keep the fittest offspring when minimizing", func(t *testing.T) {
+		parents := genomes(model.Minimize, 0, 0, 0)
+		next := genomes(model.Minimize, 0, 0, 0)
+		require.NoError(t, s.Replace(parents, genomes(model.Minimize, 4, 1, 5, 2, 3), &next))
+
+		assert.Equal(t, []byte{1, 2, 3}, genes(next))
+	})
+	t.Run("should fail with less offspring than parents", func(t *testing.T) {
+		parents := genomes(model.Maximize, 1, 2, 3)
+		next := genomes(model.Maximize, 0, 0, 0)
+		assert.Error(t, s.Replace(parents, genomes(model.Maximize, 1, 2), &next))
+	})
+	t.Run("should only validate populations up to lambda", func(t *testing.T) {
+		assert.NoError(t, replacement.Validate(s, 5))
+		assert.Error(t, replacement.Validate(s, 6))
+		assert.NoError(t, replacement.Validate(replacement.MuPlusLambda(1), 6))
+	})
+}
+
+func TestMuPlusLambda(t *testing.T) {
+	s := replacement.MuPlusLambda(2)
+	assert.Equal(t, 2, s.Offspring(3))
+
+	t.Run("should keep the fittest among parents and offspring", func(t *testing.T) {
+		parents := genomes(model.Maximize, 1, 7, 3)
+		next := genomes(model.Maximize, 0, 0, 0)
+		require.NoError(t, s.Replace(parents, genomes(model.Maximize, 2, 5), &next))
+
+		assert.Equal(t, []byte{7, 5, 3}, genes(next))
+	})
+	t.Run("should keep the fittest when minimizing", func(t *testing.T) {
+		parents := genomes(model.Minimize, 1, 7, 3)
+		next := genomes(model.Minimize, 0, 0, 0)
+		require.NoError(t, s.Replace(parents, genomes(model.Minimize, 2, 5), &next))
+
+		assert.Equal(t, []byte{1, 2, 3}, genes(next))
+	})
+}
@@ -0,0 +1,175 @@
+package scaling
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/mbolis/genetta/model"
+)
+
+// Operator maps raw fitness values to non-negative values, larger being
+// better whatever the direction of the raw ones, that are only meant to drive
+// selection.
+type Operator interface {
+	Scale(g *model.Genomes, generation int) error
+}
+
+type linear struct {
+	c float64
+}
+
+// Linear scaling keeps the mean fitness unchanged, and maps the best fitness
+// to c times the mean, c usually being within [1.2, 2]. When that would make
+// some fitness negative, the worst fitness is mapped to 0 instead.
+func Linear(c float64) Operator {
+	if c <= 1 {
+		panic(fmt.Sprintf("invalid linear scaling factor: %f", c)) // TODO
+	}
+	return linear{c}
+}
+
+func (l linear) Scale(g *model.Genomes, _ int) error {
+	g.MakeFitnessPositive()
+
+	stats := g.Stats()
+	fMin, fMax, fAvg := stats.MinFitness, stats.MaxFitness, stats.Mean
+	if fMax == fAvg {
+		return nil
+	}
+
+	var a, b float64
+	if fMin > (l.c*fAvg-fMax)/(l.c-1) {
+		delta := fMax - fAvg
+		a = (l.c - 1) * fAvg / delta
+		b = fAvg * (fMax - l.c*fAvg) / delta
+	} else {
+		delta := fAvg - fMin
+		a = fAvg / delta
+		b = -fMin * fAvg / delta
+	}
+
+	g.Rescale(func(_ int, f float64) float64 {
+		return max(0, a*f+b)
+	})
+	return nil
+}
+
+type sigmaTruncation struct {
+	c float64
+}
+
+// SigmaTruncation subtracts from each fitness the mean minus c standard
+// deviations, truncating the results below 0.
+func SigmaTruncation(c float64) Operator {
+	if c <= 0 {
+		panic(fmt.Sprintf("invalid sigma truncation factor: %f", c)) // TODO
+	}
+	return sigmaTruncation{c}
+}
+
+func (s sigmaTruncation) Scale(g *model.Genomes, _ int) error {
+	g.MakeFitnessPositive()
+
+	stats := g.Stats()
+	base := stats.Mean - s.c*stats.StandardDeviation()
+
+	g.Rescale(func(_ int, f float64) float64 {
+		return max(0, f-base)
+	})
+	return nil
+}
+
+type powerLaw struct {
+	k float64
+}
+
+// PowerLaw raises each fitness to the power of k, after making all of them
+// non-negative.
+func PowerLaw(k float64) Operator {
+	if k <= 0 {
+		panic(fmt.Sprintf("invalid power law exponent: %f", k)) // TODO
+	}
+	return powerLaw{k}
+}
+
+func (p powerLaw) Scale(g *model.Genomes, _ int) error {
+	g.MakeFitnessPositive()
+
+	g.Rescale(func(_ int, f float64) float64 {
+		return math.Pow(f, p.k)
+	})
+	return nil
+}
+
+type rank struct {
+	pressure float64
+	indices  []int
+	ranks    []int
+}
+
+// Rank replaces each fitness with a linear function of its rank, so that the
+// best individual gets the selection pressure, within [1, 2], and the worst
+// one gets 2 minus the pressure.
+func Rank(pressure float64) Operator {
+	if pressure < 1 || pressure > 2 {
+		panic(fmt.Sprintf("invalid rank selection pressure: %f", pressure)) // TODO
+	}
+	return &rank{pressure: pressure}
+}
+
+func (r *rank) Scale(g *model.Genomes, _ int) error {
+	n := g.NIndividuals()
+	if len(r.indices) < n {
+		r.indices = make([]int, n)
+		r.ranks = make([]int, n)
+	}
+
+	for rank, i := range g.SortedIndices(r.indices) {
+		r.ranks[i] = rank
+	}
+
+	step := 0.0
+	if n > 1 {
+		step = 2 * (r.pressure - 1) / float64(n-1)
+	}
+	g.Rescale(func(i int, _ float64) float64 {
+		return r.pressure - step*float64(r.ranks[i])
+	})
+	return nil
+}
+
+type boltzmann struct {
+	temperature func(generation int) float64
+}
+
+// Boltzmann replaces each fitness f with exp(f/T), T being given for each
+// generation by the temperature schedule: high temperatures flatten the
+// differences, while low ones sharpen them.
+func Boltzmann(temperature func(generation int) float64) Operator {
+	return boltzmann{temperature}
+}
+
+// ExponentialCooling is a temperature schedule starting at t0 and multiplied
+// by rate at each generation.
+func ExponentialCooling(t0, rate float64) func(generation int) float64 {
+	return func(generation int) float64 {
+		return t0 * math.Pow(rate, float64(generation-1))
+	}
+}
+
+func (b boltzmann) Scale(g *model.Genomes, generation int) error {
+	t := b.temperature(generation)
+	if !(t > 0) {
+		return fmt.Errorf("invalid temperature at generation %d: %f", generation, t)
+	}
+
+	g.MakeFitnessPositive()
+
+	// subtracting the best fitness does not change the proportions, but
+	// keeps the exponentials from overflowing
+	_, fMax := g.Fittest()
+	g.Rescale(func(_ int, f float64) float64 {
+		return math.Exp((f - fMax) / t)
+	})
+	return nil
+}
@@ -0,0 +1,102 @@
+package scaling_test
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/mbolis/genetta/genotype"
+	"github.com/mbolis/genetta/model"
+	"github.com/mbolis/genetta/scaling"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const epsilon = 1e-9
+
+func randomGenomes(fitness ...float64) model.Genomes {
+	p := model.New(genotype.Binary[byte](8, 1), len(fitness))
+	p.Reset()
+	for i, f := range fitness {
+		p.SetFitness(i, f)
+	}
+	return p.Genomes
+}
+
+func fitnessValues(g model.Genomes) []float64 {
+	out := make([]float64, g.NIndividuals())
+	for i := range out {
+		out[i] = g.Fitness(i)
+	}
+	return out
+}
+
+func TestLinear(t *testing.T) {
+	t.Run("should keep the mean and stretch the best fitness", func(t *testing.T) {
+		g := randomGenomes(10, 11, 12, 13, 14)
+		require.NoError(t, scaling.Linear(2).Scale(&g, 1))
+
+		stats := g.Stats()
+		assert.InDelta(t, 12, stats.Mean, epsilon)
+		assert.InDelta(t, 24, stats.MaxFitness, epsilon)
+		assert.Equal(t, 4, stats.Fittest)
+	})
+	t.Run("should map the worst fitness to zero rather than below", func(t *testing.T) {
+		g := randomGenomes(10, 90, 90, 90, 90)
+		require.NoError(t, scaling.Linear(2).Scale(&g, 1))
+
+		stats := g.Stats()
+		assert.InDelta(t, 74, stats.Mean, epsilon)
+		assert.InDelta(t, 0, stats.MinFitness, epsilon)
+		assert.Less(t, stats.MaxFitness, 2*stats.Mean)
+	})
+}
+
+func TestSigmaTruncation(t *testing.T) {
+	g := randomGenomes(1, 2, 3, 4, 5)
+	require.NoError(t, scaling.SigmaTruncation(1).Scale(&g, 1))
+
+	base := 3 - math.Sqrt(2)
+	assert.InDeltaSlice(t, []float64{0, 2 - base, 3 - base, 4 - base, 5 - base}, fitnessValues(g), epsilon)
+}
+
+func TestPowerLaw(t *testing.T) {
+	g := randomGenomes(-1, 0, 1, 2)
+	require.NoError(t, scaling.PowerLaw(2).Scale(&g, 1))
+
+	assert.InDeltaSlice(t, []float64{0, 1, 4, 9}, fitnessValues(g), epsilon)
+}
+
+func TestRank(t *testing.T) {
+	g := randomGenomes(-3, 100, 0.5, 7, 1e6)
+	require.NoError(t, scaling.Rank(1.5).Scale(&g, 1))
+
+	assert.InDeltaSlice(t, []float64{0.5, 1.25, 0.75, 1, 1.5}, fitnessValues(g), epsilon)
+	assert.InDelta(t, 5, g.Stats().TotalFitness, epsilon)
+}
+
+func TestBoltzmann(t *testing.T) {
+	schedule := scaling.ExponentialCooling(10, 0.5)
+	assert.Equal(t, 10.0, schedule(1))
+	assert.Equal(t, 2.5, schedule(3))
+
+	fitness := make([]float64, 10)
+	for i := range fitness {
+		fitness[i] = rand.NormFloat64() * 10
+	}
+
+	for _, gen := range []int{1, 3, 5} {
+		g := randomGenomes(fitness...)
+		require.NoError(t, scaling.Boltzmann(schedule).Scale(&g, gen))
+
+		temperature := schedule(gen)
+		for i := 1; i < len(fitness); i++ {
+			expected := (fitness[i] - fitness[0]) / temperature
+			actual := math.Log(g.Fitness(i)) - math.Log(g.Fitness(0))
+			assert.InDelta(t, expected, actual, 1e-6)
+		}
+	}
+
+	g := randomGenomes(fitness...)
+	assert.Error(t, scaling.Boltzmann(func(int) float64 { return 0 }).Scale(&g, 1))
+}
@@ -2,7 +2,10 @@ package selection
 
 import (
 	"fmt"
+	"math"
 	"math/rand/v2"
+	"slices"
+	"sort"
 
 	"github.com/mbolis/genetta/model"
 )
@@ -25,34 +28,267 @@ func (random) SelectInto(p model.Genomes, buffer [][]byte) error {
 	return nil
 }
 
-type rouletteWheel struct{}
+type rouletteWheel struct {
+	wheel
+}
 
+// RouletteWheel picks parents with probability proportional to their fitness.
+// The wheel is built once per call, then each parent is found by binary
+// search, so filling the buffer takes O(n + m log n).
 func RouletteWheel() Operator {
-	return rouletteWheel{}
+	return &rouletteWheel{}
 }
 
-func (rouletteWheel) SelectInto(p model.Genomes, buffer [][]byte) error {
-	p.MakeFitnessPositive()
-
-	stats := p.Stats()
-	totalFitness := stats.TotalFitness
+func (r *rouletteWheel) SelectInto(p model.Genomes, buffer [][]byte) error {
+	totalFitness := p.TotalMerit()
 	if totalFitness == 0 {
 		return random{}.SelectInto(p, buffer)
 	}
 
 	nIndividuals := p.NIndividuals()
-outer:
+	r.reset(nIndividuals)
+	for idx := range nIndividuals {
+		r.set(idx, p.Merit(idx))
+	}
+	if total := r.total(); !(total > 0) || math.IsInf(total, 0) {
+		return fmt.Errorf("bad total fitness value: %f", total)
+	}
+
+	for i := range buffer {
+		buffer[i] = p.Genotype(r.spin())
+	}
+	return nil
+}
+
+type tournament struct {
+	k int
+}
+
+// Tournament picks each parent as the fittest among k individuals drawn at
+// random. It only compares fitness values, so it works with any scale, sign
+// or direction.
+func Tournament(k int) Operator {
+	if k <= 0 {
+		panic(fmt.Sprintf("invalid tournament size: %d", k)) // TODO
+	}
+	return tournament{k}
+}
+
+func (t tournament) SelectInto(p model.Genomes, buffer [][]byte) error {
+	nIndividuals := p.NIndividuals()
+	direction := p.Direction()
+	for i := range buffer {
+		winner := rand.IntN(nIndividuals)
+		for range t.k - 1 {
+			challenger := rand.IntN(nIndividuals)
+			if direction.Better(p.Fitness(challenger), p.Fitness(winner)) {
+				winner = challenger
+			}
+		}
+		buffer[i] = p.Genotype(winner)
+	}
+	return nil
+}
+
+type probabilisticTournament struct {
+	k           int
+	p           float64
+	contestants []int
+}
+
+// ProbabilisticTournament draws k individuals at random, then picks the
+// fittest with probability p, the second fittest with probability p(1-p), and
+// so on, the last one getting the remaining probability.
+func ProbabilisticTournament(k int, p float64) Operator {
+	if k <= 0 {
+		panic(fmt.Sprintf("invalid tournament size: %d", k)) // TODO
+	}
+	if p <= 0 || p > 1 {
+		panic(fmt.Sprintf("invalid tournament probability: %f", p)) // TODO
+	}
+	return &probabilisticTournament{k: k, p: p, contestants: make([]int, k)}
+}
+
+func (t *probabilisticTournament) SelectInto(p model.Genomes, buffer [][]byte) error {
+	nIndividuals := p.NIndividuals()
+	direction := p.Direction()
 	for i := range buffer {
-		selection := rand.Float64() * totalFitness
-		initial := selection
-		for idx := range nIndividuals {
-			selection -= p.Fitness(idx)
-			if selection <= 0 {
-				buffer[i] = p.Genotype(idx)
-				continue outer
+		for j := range t.contestants {
+			t.contestants[j] = rand.IntN(nIndividuals)
+		}
+		slices.SortFunc(t.contestants, func(a, b int) int {
+			return direction.Compare(p.Fitness(b), p.Fitness(a))
+		})
+
+		winner := t.contestants[t.k-1]
+		for _, c := range t.contestants[:t.k-1] {
+			if rand.Float64() < t.p {
+				winner = c
+				break
 			}
 		}
-		return fmt.Errorf("bad total fitness value: %f, selection: from %f down to %f", totalFitness, initial, selection)
+		buffer[i] = p.Genotype(winner)
+	}
+	return nil
+}
+
+type truncation struct {
+	ratio   float64
+	indices []int
+}
+
+// Truncation picks parents uniformly at random among the fittest share of the
+// population given by ratio, within (0, 1].
+func Truncation(ratio float64) Operator {
+	if ratio <= 0 || ratio > 1 {
+		panic(fmt.Sprintf("invalid truncation ratio: %f", ratio)) // TODO
+	}
+	return &truncation{ratio: ratio}
+}
+
+func (t *truncation) SelectInto(p model.Genomes, buffer [][]byte) error {
+	n := p.NIndividuals()
+	if cap(t.indices) < n {
+		t.indices = make([]int, n)
+	}
+
+	indices := p.SortedIndices(t.indices)
+	top := max(1, int(math.Ceil(t.ratio*float64(n))))
+	for i := range buffer {
+		buffer[i] = p.Genotype(indices[rand.IntN(top)])
 	}
 	return nil
 }
+
+// wheel picks indices with probability proportional to their weights, by
+// binary search on the cumulative weights.
+type wheel struct {
+	cumulative []float64
+}
+
+func (w *wheel) reset(n int) {
+	if cap(w.cumulative) < n {
+		w.cumulative = make([]float64, n)
+	}
+	w.cumulative = w.cumulative[:n]
+}
+
+func (w *wheel) set(i int, weight float64) {
+	if i > 0 {
+		weight += w.cumulative[i-1]
+	}
+	w.cumulative[i] = weight
+}
+
+func (w wheel) total() float64 {
+	return w.cumulative[len(w.cumulative)-1]
+}
+
+func (w wheel) spin() int {
+	selection := rand.Float64() * w.total()
+	i := sort.Search(len(w.cumulative), func(i int) bool {
+		return w.cumulative[i] > selection
+	})
+	if i == len(w.cumulative) {
+		// rounding errors: fall back on the last individual with some weight
+		total := w.total()
+		i = sort.Search(len(w.cumulative), func(i int) bool {
+			return w.cumulative[i] >= total
+		})
+	}
+	return i
+}
+
+type rankSelection struct {
+	weight  func(rank, n int) float64
+	indices []int
+	wheel
+}
+
+func (r *rankSelection) SelectInto(p model.Genomes, buffer [][]byte) error {
+	n := p.NIndividuals()
+	if cap(r.indices) < n {
+		r.indices = make([]int, n)
+	}
+
+	indices := p.SortedIndices(r.indices)
+	r.reset(n)
+	for rank := range indices {
+		r.set(rank, r.weight(rank, n))
+	}
+
+	for i := range buffer {
+		buffer[i] = p.Genotype(indices[r.spin()])
+	}
+	return nil
+}
+
+// LinearRank picks parents with a probability decreasing linearly with their
+// rank, the fittest being pressure times as likely as the average, and the
+// worst 2-pressure times; pressure must be within [1, 2].
+func LinearRank(pressure float64) Operator {
+	if pressure < 1 || pressure > 2 {
+		panic(fmt.Sprintf("invalid rank selection pressure: %f", pressure)) // TODO
+	}
+
+	return &rankSelection{
+		weight: func(rank, n int) float64 {
+			if n == 1 {
+				return 1
+			}
+			return pressure - 2*(pressure-1)*float64(rank)/float64(n-1)
+		},
+	}
+}
+
+// ExponentialRank picks parents with a probability proportional to base^rank,
+// the fittest having rank 0; base must be within (0, 1).
+func ExponentialRank(base float64) Operator {
+	if base <= 0 || base >= 1 {
+		panic(fmt.Sprintf("invalid exponential rank base: %f", base)) // TODO
+	}
+
+	return &rankSelection{
+		weight: func(rank, _ int) float64 {
+			return math.Pow(base, float64(rank))
+		},
+	}
+}
+
+type stochasticUniversalSampling struct{}
+
+// StochasticUniversalSampling picks parents with probability proportional to
+// their fitness like RouletteWheel does, but fills the whole buffer in a
+// single pass, with evenly spaced pointers: each individual is picked either
+// the floor or the ceiling of its expected number of times.
+func StochasticUniversalSampling() Operator {
+	return stochasticUniversalSampling{}
+}
+
+func (stochasticUniversalSampling) SelectInto(p model.Genomes, buffer [][]byte) error {
+	totalFitness := p.TotalMerit()
+	if totalFitness == 0 {
+		return random{}.SelectInto(p, buffer)
+	}
+
+	step := totalFitness / float64(len(buffer))
+	pointer := rand.Float64() * step
+
+	var cumulative float64
+	idx := -1
+	nIndividuals := p.NIndividuals()
+	for i := range buffer {
+		for cumulative <= pointer && idx < nIndividuals-1 {
+			idx++
+			cumulative += p.Merit(idx)
+		}
+		buffer[i] = p.Genotype(idx)
+		pointer += step
+	}
+
+	// consecutive slots hold the same individuals, which would then be mated
+	rand.Shuffle(len(buffer), func(i, j int) {
+		buffer[i], buffer[j] = buffer[j], buffer[i]
+	})
+	return nil
+}
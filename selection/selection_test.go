@@ -1,6 +1,7 @@
 package selection_test
 
 import (
+	"fmt"
 	"math"
 	"math/rand/v2"
 	"testing"
@@ -12,6 +13,7 @@ import (
 	"github.com/mbolis/genetta/model"
 	"github.com/mbolis/genetta/selection"
 	"github.com/stretchr/testify/assert"
+	"gonum.org/v1/gonum/stat/combin"
 )
 
 const repeats = 10_000
@@ -69,3 +71,249 @@ func TestRouletteWheel(t *testing.T) {
 	)
 	assert.Greater(t, pValue, 0.05)
 }
+
+func TestRouletteWheelWithoutMerit(t *testing.T) {
+	r := selection.RouletteWheel()
+
+	// when minimizing, the worst individual has no merit at all
+	population := model.New(genotype.Binary[byte](8, 1), 1000)
+	population.SetDirection(model.Minimize)
+	population.Reset()
+	for i := range population.NIndividuals() {
+		population.Encode(i, []byte{byte(i % 256)})
+		population.SetFitness(i, float64(i%4))
+	}
+
+	for range 100 {
+		var buffer [1000][]byte
+
+		err := r.SelectInto(population.Genomes, buffer[:])
+		assert.NoError(t, err)
+
+		for _, g := range buffer {
+			assert.NotEqual(t, byte(3), g[0]%4)
+		}
+	}
+}
+
+// ranks returns the rank of each individual, the fittest being 0
+func ranks(p model.Genomes) []int {
+	ranks := make([]int, p.NIndividuals())
+	for r, i := range p.SortedIndices(make([]int, p.NIndividuals())) {
+		ranks[i] = r
+	}
+	return ranks
+}
+
+// tournamentProb returns the probability that the individual of rank r out of
+// n is picked by a tournament of size k where the j-th best contestant wins
+// with probability q(j)
+func tournamentProb(n, k, r int, q func(j int) float64) (prob float64) {
+	better := float64(r) / float64(n)
+	same := 1 / float64(n)
+	worse := float64(n-r-1) / float64(n)
+
+	// x contestants better than r, y contestants equal to r
+	for x := 0; x <= k; x++ {
+		for y := 1; x+y <= k; y++ {
+			z := k - x - y
+			ways := combin.Binomial(k, x) * combin.Binomial(k-x, y)
+			pDraw := float64(ways) * math.Pow(better, float64(x)) * math.Pow(same, float64(y)) * math.Pow(worse, float64(z))
+
+			var pWin float64
+			for j := x + 1; j <= x+y; j++ {
+				pWin += q(j)
+			}
+			prob += pDraw * pWin
+		}
+	}
+	return
+}
+
+func TestTournament(t *testing.T) {
+	const n = 16
+
+	for _, k := range []int{3} {
+		for _, minimize := range []bool{false, true} {
+			t.Run(fmt.Sprintf("should pick the best of %d (minimize: %v)", k, minimize), func(t *testing.T) {
+				r := selection.Tournament(k)
+
+				population := randomPopulation(n)
+				for i := range population.NIndividuals() {
+					population.SetFitness(i, rand.NormFloat64())
+				}
+				if minimize {
+					population.SetDirection(model.Minimize)
+				}
+
+				var counts [n]float64
+				for range repeats {
+					var buffer [n][]byte
+
+					err := r.SelectInto(population.Genomes, buffer[:])
+					assert.NoError(t, err)
+
+					for _, g := range buffer {
+						counts[g[0]-1]++
+					}
+				}
+
+				ranks := ranks(population.Genomes)
+				_, pValue := stats.ChiSquarePFunc(counts[:], func(i int) float64 {
+					return n * repeats * tournamentProb(n, k, ranks[i], func(j int) float64 {
+						if j == 1 {
+							return 1
+						}
+						return 0
+					})
+				})
+				assert.Greater(t, pValue, 0.05)
+			})
+		}
+	}
+}
+
+func TestProbabilisticTournament(t *testing.T) {
+	const n = 16
+
+	for _, k := range []int{3} {
+		for _, p := range []float64{0.75} {
+			t.Run(fmt.Sprintf("should pick the best of %d with probability %g", k, p), func(t *testing.T) {
+				r := selection.ProbabilisticTournament(k, p)
+
+				population := randomPopulation(n)
+				for i := range population.NIndividuals() {
+					population.SetFitness(i, rand.NormFloat64())
+				}
+
+				var counts [n]float64
+				for range repeats {
+					var buffer [n][]byte
+
+					err := r.SelectInto(population.Genomes, buffer[:])
+					assert.NoError(t, err)
+
+					for _, g := range buffer {
+						counts[g[0]-1]++
+					}
+				}
+
+				ranks := ranks(population.Genomes)
+				_, pValue := stats.ChiSquarePFunc(counts[:], func(i int) float64 {
+					return n * repeats * tournamentProb(n, k, ranks[i], func(j int) float64 {
+						if j == k {
+							return math.Pow(1-p, float64(k-1))
+						}
+						return p * math.Pow(1-p, float64(j-1))
+					})
+				})
+				assert.Greater(t, pValue, 0.05)
+			})
+		}
+	}
+}
+
+func assertRankDistribution(t *testing.T, op selection.Operator, weight func(rank int) float64) {
+	t.Helper()
+	const n = 32
+
+	population := randomPopulation(n)
+	for i := range population.NIndividuals() {
+		population.SetFitness(i, rand.NormFloat64())
+	}
+
+	var counts [n]float64
+	for range repeats {
+		var buffer [n][]byte
+
+		err := op.SelectInto(population.Genomes, buffer[:])
+		assert.NoError(t, err)
+
+		for _, g := range buffer {
+			counts[g[0]-1]++
+		}
+	}
+
+	var totalWeight float64
+	for r := range n {
+		totalWeight += weight(r)
+	}
+
+	ranks := ranks(population.Genomes)
+	_, pValue := stats.ChiSquarePFunc(counts[:], func(i int) float64 {
+		return n * repeats * weight(ranks[i]) / totalWeight
+	})
+	assert.Greater(t, pValue, 0.05)
+}
+
+func TestLinearRank(t *testing.T) {
+	for _, pressure := range []float64{1.5} {
+		t.Run(fmt.Sprintf("should pick by linear rank with pressure %.1f", pressure), func(t *testing.T) {
+			assertRankDistribution(t, selection.LinearRank(pressure), func(rank int) float64 {
+				return pressure - 2*(pressure-1)*float64(rank)/31
+			})
+		})
+	}
+}
+
+func TestExponentialRank(t *testing.T) {
+	for _, base := range []float64{0.9} {
+		t.Run(fmt.Sprintf("should pick by exponential rank with base %.1f", base), func(t *testing.T) {
+			assertRankDistribution(t, selection.ExponentialRank(base), func(rank int) float64 {
+				return math.Pow(base, float64(rank))
+			})
+		})
+	}
+}
+
+func TestStochasticUniversalSampling(t *testing.T) {
+	r := selection.StochasticUniversalSampling()
+
+	var counts [128]float64
+	population := randomPopulation(128)
+	for range repeats {
+		var buffer [128][]byte
+
+		err := r.SelectInto(population.Genomes, buffer[:])
+		assert.NoError(t, err)
+
+		var runCounts [128]float64
+		for _, g := range buffer {
+			runCounts[g[0]-1]++
+		}
+		for i, c := range runCounts {
+			expected := population.Fitness(i) / population.Stats().TotalFitness * 128
+			assert.InDelta(t, expected, c, 1, "each individual should be picked floor or ceiling of the expected times")
+			counts[i] += c
+		}
+	}
+
+	_, pValue := stats.ChiSquarePFunc(counts[:], func(i int) float64 {
+		return population.Fitness(i) / population.Stats().TotalFitness * 128 * repeats
+	})
+	assert.Greater(t, pValue, 0.05)
+}
+
+func TestTruncation(t *testing.T) {
+	r := selection.Truncation(0.25)
+
+	population := randomPopulation(128)
+	rank := ranks(population.Genomes)
+
+	uniform := distcheck.Uniform(0, 31)
+	for range repeats {
+		var buffer [128][]byte
+
+		err := r.SelectInto(population.Genomes, buffer[:])
+		assert.NoError(t, err)
+
+		for _, g := range buffer {
+			if !assert.Less(t, rank[g[0]-1], 32) {
+				return
+			}
+			uniform.Offer(rank[g[0]-1])
+		}
+	}
+
+	uniform.Assert(t)
+}
@@ -0,0 +1,220 @@
+package termination
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mbolis/genetta/model"
+)
+
+// State describes a run right after the evaluation of a generation.
+type State struct {
+	Generation  int
+	Evaluations int
+	Elapsed     time.Duration
+
+	Stats   model.Stats
+	Genomes model.Genomes
+}
+
+type Criterion interface {
+	// Check returns the criterion that fired, or nil if the run should go on.
+	Check(State) Criterion
+	fmt.Stringer
+}
+
+type fitnessReached struct {
+	threshold float64
+	tolerance float64
+}
+
+// FitnessReached fires as soon as the best fitness gets within tolerance of
+// the threshold, or beyond it in the direction of optimization.
+func FitnessReached(threshold, tolerance float64) Criterion {
+	if tolerance < 0 {
+		panic(fmt.Sprintf("invalid tolerance: %f", tolerance)) // TODO
+	}
+	return fitnessReached{threshold, tolerance}
+}
+
+func (f fitnessReached) Check(s State) Criterion {
+	best := s.Stats.BestFitness
+	if s.Stats.Direction == model.Minimize {
+		if best <= f.threshold+f.tolerance {
+			return f
+		}
+	} else if best >= f.threshold-f.tolerance {
+		return f
+	}
+	return nil
+}
+
+func (f fitnessReached) String() string {
+	if f.tolerance == 0 {
+		return fmt.Sprintf("fitness reaching %g", f.threshold)
+	}
+	return fmt.Sprintf("fitness reaching %g ± %g", f.threshold, f.tolerance)
+}
+
+type maxGenerations int
+
+// MaxGenerations fires once n generations have been evaluated.
+func MaxGenerations(n int) Criterion {
+	return maxGenerations(n)
+}
+
+func (m maxGenerations) Check(s State) Criterion {
+	if s.Generation >= int(m) {
+		return m
+	}
+	return nil
+}
+
+func (m maxGenerations) String() string {
+	return fmt.Sprintf("%d generations", int(m))
+}
+
+type timeBudget time.Duration
+
+// TimeBudget fires once the run has been going on for at least d.
+func TimeBudget(d time.Duration) Criterion {
+	return timeBudget(d)
+}
+
+func (t timeBudget) Check(s State) Criterion {
+	if s.Elapsed >= time.Duration(t) {
+		return t
+	}
+	return nil
+}
+
+func (t timeBudget) String() string {
+	return fmt.Sprintf("time budget of %s", time.Duration(t))
+}
+
+type maxEvaluations int
+
+// MaxEvaluations fires once the fitness function has been called n times.
+func MaxEvaluations(n int) Criterion {
+	return maxEvaluations(n)
+}
+
+func (m maxEvaluations) Check(s State) Criterion {
+	if s.Evaluations >= int(m) {
+		return m
+	}
+	return nil
+}
+
+func (m maxEvaluations) String() string {
+	return fmt.Sprintf("%d fitness evaluations", int(m))
+}
+
+type stagnation struct {
+	generations int
+
+	best  float64
+	since int
+	ready bool
+}
+
+// Stagnation fires when the best fitness has not improved for n generations.
+// It keeps track of the best fitness, so it must not be shared among solvers.
+func Stagnation(n int) Criterion {
+	if n <= 0 {
+		panic(fmt.Sprintf("invalid stagnation length: %d", n)) // TODO
+	}
+	return &stagnation{generations: n}
+}
+
+func (st *stagnation) Check(s State) Criterion {
+	if !st.ready || s.Stats.Direction.Better(s.Stats.BestFitness, st.best) {
+		st.best = s.Stats.BestFitness
+		st.since = s.Generation
+		st.ready = true
+		return nil
+	}
+
+	if s.Generation-st.since >= st.generations {
+		return st
+	}
+	return nil
+}
+
+func (st *stagnation) String() string {
+	return fmt.Sprintf("no improvement in %d generations", st.generations)
+}
+
+type diversityCollapse float64
+
+// DiversityCollapse fires when the genetic diversity of the population, as
+// measured by model.Genomes.Diversity, drops below threshold.
+func DiversityCollapse(threshold float64) Criterion {
+	return diversityCollapse(threshold)
+}
+
+func (d diversityCollapse) Check(s State) Criterion {
+	if s.Genomes.Diversity() < float64(d) {
+		return d
+	}
+	return nil
+}
+
+func (d diversityCollapse) String() string {
+	return fmt.Sprintf("diversity < %g", float64(d))
+}
+
+type or []Criterion
+
+// Or fires as soon as any of the criteria does, and reports the first one
+// that fired. All criteria are checked anyway, so that stateful ones stay up
+// to date.
+func Or(criteria ...Criterion) Criterion {
+	return or(criteria)
+}
+
+func (o or) Check(s State) (fired Criterion) {
+	for _, c := range o {
+		if f := c.Check(s); f != nil && fired == nil {
+			fired = f
+		}
+	}
+	return
+}
+
+func (o or) String() string {
+	return join(o, " OR ")
+}
+
+type and []Criterion
+
+// And fires when all of the criteria do at the same time.
+func And(criteria ...Criterion) Criterion {
+	return and(criteria)
+}
+
+func (a and) Check(s State) Criterion {
+	fired := true
+	for _, c := range a {
+		if c.Check(s) == nil {
+			fired = false
+		}
+	}
+	if fired {
+		return a
+	}
+	return nil
+}
+
+func (a and) String() string {
+	return join(a, " AND ")
+}
+
+func join(criteria []Criterion, sep string) string {
+	parts := make([]string, len(criteria))
+	for i, c := range criteria {
+		parts[i] = c.String()
+	}
+	return "(" + strings.Join(parts, sep) + ")"
+}
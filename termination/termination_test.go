@@ -0,0 +1,104 @@
+package termination_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mbolis/genetta/genotype"
+	"github.com/mbolis/genetta/model"
+	"github.com/mbolis/genetta/termination"
+	"github.com/stretchr/testify/assert"
+)
+
+func stateWithFitness(generation int, bestFitness float64) termination.State {
+	return termination.State{
+		Generation: generation,
+		Stats:      model.Stats{BestFitness: bestFitness},
+	}
+}
+
+func minimizing(s termination.State) termination.State {
+	s.Stats.Direction = model.Minimize
+	return s
+}
+
+func TestFitnessReached(t *testing.T) {
+	c := termination.FitnessReached(1, 0.01)
+
+	t.Run("should fire when maximizing", func(t *testing.T) {
+		assert.Nil(t, c.Check(stateWithFitness(1, 0.98)))
+		assert.Equal(t, c, c.Check(stateWithFitness(1, 0.995)))
+		assert.Equal(t, c, c.Check(stateWithFitness(1, 1.5)))
+	})
+	t.Run("should fire when minimizing", func(t *testing.T) {
+		assert.Nil(t, c.Check(minimizing(stateWithFitness(1, 1.02))))
+		assert.Equal(t, c, c.Check(minimizing(stateWithFitness(1, 1.005))))
+		assert.Equal(t, c, c.Check(minimizing(stateWithFitness(1, 0.5))))
+	})
+}
+
+func TestLimits(t *testing.T) {
+	t.Run("should stop after max generations", func(t *testing.T) {
+		c := termination.MaxGenerations(10)
+		assert.Nil(t, c.Check(termination.State{Generation: 9}))
+		assert.Equal(t, c, c.Check(termination.State{Generation: 10}))
+	})
+	t.Run("should stop after max evaluations", func(t *testing.T) {
+		c := termination.MaxEvaluations(1000)
+		assert.Nil(t, c.Check(termination.State{Evaluations: 999}))
+		assert.Equal(t, c, c.Check(termination.State{Evaluations: 1000}))
+	})
+	t.Run("should stop after time budget", func(t *testing.T) {
+		c := termination.TimeBudget(time.Second)
+		assert.Nil(t, c.Check(termination.State{Elapsed: time.Second - 1}))
+		assert.Equal(t, c, c.Check(termination.State{Elapsed: time.Second}))
+	})
+}
+
+func TestStagnation(t *testing.T) {
+	c := termination.Stagnation(3)
+
+	assert.Nil(t, c.Check(stateWithFitness(1, 1)))
+	assert.Nil(t, c.Check(stateWithFitness(2, 2)))
+	assert.Nil(t, c.Check(stateWithFitness(3, 2)))
+	assert.Nil(t, c.Check(stateWithFitness(4, 1.5)))
+	assert.Equal(t, c, c.Check(stateWithFitness(5, 2)))
+
+	c = termination.Stagnation(2)
+	assert.Nil(t, c.Check(minimizing(stateWithFitness(1, 2))))
+	assert.Nil(t, c.Check(minimizing(stateWithFitness(2, 1))))
+	assert.Nil(t, c.Check(minimizing(stateWithFitness(3, 1.5))))
+	assert.Equal(t, c, c.Check(minimizing(stateWithFitness(4, 1))))
+}
+
+func TestDiversityCollapse(t *testing.T) {
+	c := termination.DiversityCollapse(0.1)
+
+	p := model.New(genotype.Binary[uint8](8, 4), 100)
+	assert.Nil(t, c.Check(termination.State{Genomes: p.Genomes}))
+
+	for i := range p.NIndividuals() {
+		p.Encode(i, []uint8{1, 2, 3, 4})
+	}
+	assert.Equal(t, c, c.Check(termination.State{Genomes: p.Genomes}))
+}
+
+func TestCombinators(t *testing.T) {
+	generations := termination.MaxGenerations(10)
+	fitness := termination.FitnessReached(1, 0)
+
+	t.Run("should stop when any criterion fires", func(t *testing.T) {
+		c := termination.Or(generations, fitness)
+		assert.Nil(t, c.Check(stateWithFitness(5, 0.5)))
+		assert.Equal(t, fitness, c.Check(stateWithFitness(5, 1)))
+		assert.Equal(t, generations, c.Check(stateWithFitness(10, 0.5)))
+		assert.Equal(t, generations, c.Check(stateWithFitness(10, 1)))
+	})
+	t.Run("should stop when all criteria fire", func(t *testing.T) {
+		c := termination.And(generations, fitness)
+		assert.Nil(t, c.Check(stateWithFitness(5, 1)))
+		assert.Nil(t, c.Check(stateWithFitness(10, 0.5)))
+		assert.Equal(t, c, c.Check(stateWithFitness(10, 1)))
+		assert.Equal(t, "(10 generations AND fitness reaching 1)", c.String())
+	})
+}